@@ -0,0 +1,31 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+import "runtime/debug"
+
+// readBuildInfo returns the module version and the VCS revision and time
+// embedded by the go command.
+func readBuildInfo() (version, revision, time string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", "", ""
+	}
+	version = info.Main.Version
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.time":
+			time = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && revision != "" {
+		revision += "-dirty"
+	}
+	return version, revision, time
+}
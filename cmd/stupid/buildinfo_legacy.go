@@ -0,0 +1,10 @@
+//go:build !go1.18
+// +build !go1.18
+
+package main
+
+// readBuildInfo returns nothing as the go command does not embed the build
+// information before Go 1.18.
+func readBuildInfo() (version, revision, time string) {
+	return "", "", ""
+}
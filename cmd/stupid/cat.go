@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func cat(args []string) error {
+	flags := newFlagSet("cat")
+	out := flags.String("out", "-", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	return catFiles(os.Stdout, *out, args...)
+}
+
+// catFiles concatenates srcs to dst, or to stdout if dst is "-".
+func catFiles(stdout io.Writer, dst string, srcs ...string) error {
+	srcs, err := glob(srcs, true)
+	if err != nil {
+		return err
+	}
+	if dst == "-" {
+		return catAll(stdout, srcs)
+	}
+	dst, err = expand(dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Concatenating [%v]\n", dst)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err := catAll(f, srcs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func catAll(w io.Writer, srcs []string) error {
+	for _, src := range srcs {
+		if err := catFile(w, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func catFile(w io.Writer, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func chmod(args []string) error {
+	flags := newFlagSet("chmod")
+	recursive := flags.Bool("R", false, "")
+	// A mode starting with a dash, e.g. -w, is not an unknown flag.
+	mode := ""
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") || arg == "--" {
+			break
+		}
+		if flags.Lookup(strings.TrimLeft(arg, "-")) != nil {
+			continue
+		}
+		if _, err := parseMode(arg, 0); err == nil {
+			mode = arg
+			args = append(args[:i:i], args[i+1:]...)
+			break
+		}
+	}
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if mode != "" {
+		args = append([]string{mode}, args...)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("Expected a mode and sources")
+	}
+	return chmodFiles(args[0], args[1:], *recursive)
+}
+
+func chmodFiles(spec string, sources []string, recursive bool) error {
+	mode, err := parseMode(spec, umask())
+	if err != nil {
+		return err
+	}
+	sources, err = glob(sources, true)
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		fmt.Printf("Changing mode of [%v] to [%v]\n", source, spec)
+		if !recursive {
+			info, err := os.Stat(source)
+			if err != nil {
+				return err
+			}
+			if err = chmodFile(source, info, mode); err != nil {
+				return err
+			}
+			continue
+		}
+		err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return chmodFile(path, info, mode)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chmodFile applies mode to path and warns if the platform did not honor it,
+// e.g. the executable bits on Windows.
+func chmodFile(path string, info os.FileInfo, mode func(uint32, bool) uint32) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	expected := mode(unixMode(info.Mode()), info.IsDir())
+	if err := os.Chmod(path, fileMode(expected)); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if actual := unixMode(info.Mode()); actual != expected {
+		fmt.Fprintf(os.Stderr, "Warning: mode of [%v] is %04o instead of %04o, some bits are not supported on this platform\n", path, actual, expected)
+	}
+	return nil
+}
+
+// parseMode parses an octal mode, e.g. 755, or a symbolic mode, e.g.
+// u+x,go-w, into a function computing the new mode of a file from its
+// current mode, both expressed with the unix permission bits. The bits set
+// in umask are left untouched by the clauses without ugoa, like chmod.
+func parseMode(spec string, umask uint32) (func(mode uint32, dir bool) uint32, error) {
+	if octal, err := strconv.ParseUint(spec, 8, 32); err == nil {
+		if octal > 07777 {
+			return nil, fmt.Errorf("Invalid mode [%v]", spec)
+		}
+		return func(uint32, bool) uint32 { return uint32(octal) }, nil
+	}
+	var clauses []func(uint32, bool) uint32
+	for _, clause := range strings.Split(spec, ",") {
+		f, err := parseModeClause(clause, umask)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid mode [%v]", spec)
+		}
+		clauses = append(clauses, f)
+	}
+	return func(mode uint32, dir bool) uint32 {
+		for _, clause := range clauses {
+			mode = clause(mode, dir)
+		}
+		return mode
+	}, nil
+}
+
+func parseModeClause(clause string, umask uint32) (func(uint32, bool) uint32, error) {
+	var who uint32
+	i := 0
+	for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) >= 0; i++ {
+		switch clause[i] {
+		case 'u':
+			who |= 04700
+		case 'g':
+			who |= 02070
+		case 'o':
+			who |= 01007
+		case 'a':
+			who |= 07777
+		}
+	}
+	// cleared are the bits replaced by =, including the special bits of the
+	// classes being set.
+	cleared := who
+	if who == 0 {
+		cleared, who = 07777, 07777&^umask
+	}
+	if i == len(clause) {
+		return nil, fmt.Errorf("Missing operator")
+	}
+	type action struct {
+		op    byte
+		perms string
+	}
+	var actions []action
+	for i < len(clause) {
+		op := clause[i]
+		if op != '+' && op != '-' && op != '=' {
+			return nil, fmt.Errorf("Invalid operator")
+		}
+		j := i + 1
+		for ; j < len(clause) && strings.IndexByte("rwxXst", clause[j]) >= 0; j++ {
+		}
+		actions = append(actions, action{op, clause[i+1 : j]})
+		i = j
+	}
+	return func(mode uint32, dir bool) uint32 {
+		for _, a := range actions {
+			var bits uint32
+			for _, p := range a.perms {
+				switch p {
+				case 'r':
+					bits |= 0444
+				case 'w':
+					bits |= 0222
+				case 'x':
+					bits |= 0111
+				case 'X':
+					if dir || mode&0111 != 0 {
+						bits |= 0111
+					}
+				case 's':
+					bits |= 06000
+				case 't':
+					bits |= 01000
+				}
+			}
+			bits &= who
+			switch a.op {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				mode = mode&^cleared | bits
+			}
+		}
+		return mode
+	}, nil
+}
+
+func unixMode(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
+
+func fileMode(bits uint32) os.FileMode {
+	mode := os.FileMode(bits & 0777)
+	if bits&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if bits&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if bits&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
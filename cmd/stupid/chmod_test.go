@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, tc := range []struct {
+		spec     string
+		mode     uint32
+		dir      bool
+		umask    uint32
+		expected uint32
+	}{
+		{spec: "755", mode: 0600, expected: 0755},
+		{spec: "0644", mode: 0777, expected: 0644},
+		{spec: "4755", mode: 0644, expected: 04755},
+		{spec: "u+x", mode: 0644, expected: 0744},
+		{spec: "+x", mode: 0644, expected: 0755},
+		{spec: "a+x", mode: 0644, expected: 0755},
+		{spec: "u+x,go-w", mode: 0666, expected: 0744},
+		{spec: "go=r", mode: 0777, expected: 0744},
+		{spec: "o=", mode: 0777, expected: 0770},
+		{spec: "u=rwx,g=rx,o=", mode: 0, expected: 0750},
+		{spec: "a+X", mode: 0644, expected: 0644},
+		{spec: "a+X", mode: 0644, dir: true, expected: 0755},
+		{spec: "a+X", mode: 0744, expected: 0755},
+		{spec: "u+s,+t", mode: 0755, expected: 05755},
+		{spec: "u-w+x", mode: 0644, expected: 0544},
+		{spec: "+x", mode: 0644, umask: 022, expected: 0755},
+		{spec: "+w", mode: 0444, umask: 022, expected: 0644},
+		{spec: "-w", mode: 0666, umask: 022, expected: 0466},
+		{spec: "=r", mode: 04777, umask: 077, expected: 0400},
+		{spec: "a+w", mode: 0444, umask: 022, expected: 0666},
+		{spec: "u=rwx", mode: 06755, expected: 02755},
+		{spec: "g=", mode: 06775, expected: 04705},
+	} {
+		mode, err := parseMode(tc.spec, tc.umask)
+		assert.NilError(t, err)
+		assert.Equal(t, mode(tc.mode, tc.dir), tc.expected, tc.spec)
+	}
+	for _, spec := range []string{"", "u", "u+x,", "z+x", "u*x", "u+y", "17777"} {
+		_, err := parseMode(spec, 0)
+		assert.Error(t, err, "Invalid mode ["+spec+"]")
+	}
+}
+
+func TestChmodRecursive(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("bin",
+			fs.WithMode(0755),
+			fs.WithFile("tool", "", fs.WithMode(0644)),
+			fs.WithDir("sub",
+				fs.WithMode(0755),
+				fs.WithFile("other", "", fs.WithMode(0600)))),
+		fs.WithFile("readme.txt", "", fs.WithMode(0644)))
+	defer rootDirectory.Remove()
+
+	err := chmodFiles("u+x,go-rwx", []string{filepath.Join(rootDirectory.Path(), "b*")}, true)
+	assert.NilError(t, err)
+
+	expected := fs.Expected(t,
+		fs.WithDir("bin",
+			fs.WithMode(0700),
+			fs.WithFile("tool", "", fs.WithMode(0700)),
+			fs.WithDir("sub",
+				fs.WithMode(0700),
+				fs.WithFile("other", "", fs.WithMode(0700)))),
+		fs.WithFile("readme.txt", "", fs.WithMode(0644)))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+}
+
+func TestChmodDashMode(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "", fs.WithMode(0644)),
+		fs.WithFile("bar.sh", "", fs.WithMode(0755)))
+	defer rootDirectory.Remove()
+
+	assert.NilError(t, chmod([]string{"-w", rootDirectory.Join("foo.txt")}))
+	info, err := os.Stat(rootDirectory.Join("foo.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm()&0200, os.FileMode(0))
+
+	assert.NilError(t, chmod([]string{"-R", "-x", rootDirectory.Join("bar.sh")}))
+	info, err = os.Stat(rootDirectory.Join("bar.sh"))
+	assert.NilError(t, err)
+	assert.Equal(t, info.Mode().Perm()&0100, os.FileMode(0))
+
+	assert.Error(t, chmod([]string{"-y", rootDirectory.Join("foo.txt")}), "flag provided but not defined: -y")
+}
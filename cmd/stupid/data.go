@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var dataFormats = []string{"json", "yaml", "toml", "env"}
+
+// tomlLine matches the table headers and key/value pairs of TOML.
+var tomlLine = regexp.MustCompile(`^(\[\[?[\w."' -]+\]\]?\s*(#.*)?|[\w."'-]+\s*=.*)$`)
+
+// readData reads a JSON, YAML, TOML or env file, or the standard input if
+// file is "-". The format is given by the extension, or detected from the
+// content, which must then be a mapping if it is neither JSON nor TOML.
+func readData(file string) (interface{}, error) {
+	var content []byte
+	var err error
+	if file == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else if file, err = expand(file); err == nil {
+		content, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	detected := false
+	switch format {
+	case "yml":
+		format = "yaml"
+	case "env", "json", "toml", "yaml":
+	default:
+		format, detected = dataFormat(content), true
+	}
+	var data interface{}
+	switch format {
+	case "env":
+		data, err = parseEnv(string(content))
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&data)
+	case "toml":
+		data, err = parseTOML(string(content))
+	default:
+		data, err = parseYAML(string(content))
+		// Most text is valid YAML, e.g. a single string.
+		if _, ok := data.(map[string]interface{}); detected && (err != nil || !ok) {
+			return nil, fmt.Errorf("Unknown format of [%v], expected one of %v", file, dataFormats)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read [%v]: %v", file, err)
+	}
+	return data, nil
+}
+
+// dataFormat detects whether content is JSON, TOML or else YAML, which is
+// looked at last since most documents are valid YAML.
+func dataFormat(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		if json.Valid(trimmed) {
+			return "json"
+		}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if tomlLine.MatchString(text) {
+			return "toml"
+		}
+		break
+	}
+	return "yaml"
+}
+
+// parseEnv parses KEY=VALUE lines, ignoring blank lines, comments and
+// export prefixes. Values can be single quoted, or double quoted with
+// escape sequences.
+func parseEnv(content string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		i := strings.IndexByte(text, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid line %v, expected KEY=VALUE", line)
+		}
+		key, value := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		var err error
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+			end := closingQuote(value)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated value at line %v", line)
+			}
+			if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("unexpected [%v] at line %v", rest, line)
+			}
+			if value[0] == '\'' {
+				value = value[1:end]
+			} else if value, err = strconv.Unquote(value[:end+1]); err != nil {
+				return nil, fmt.Errorf("invalid value at line %v", line)
+			}
+		} else if j := strings.Index(value, " #"); j >= 0 {
+			value = strings.TrimSpace(value[:j])
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// closingQuote returns the index of the quote closing the quoted string s
+// starts with, or -1.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type dateOptions struct {
+	utc    bool
+	unix   bool
+	format string
+	add    time.Duration
+	file   string
+}
+
+func date(args []string) error {
+	var opts dateOptions
+	flags := newFlagSet("date")
+	flags.BoolVar(&opts.utc, "utc", false, "")
+	flags.BoolVar(&opts.unix, "unix", false, "")
+	flags.StringVar(&opts.format, "format", time.RFC3339, "")
+	flags.DurationVar(&opts.add, "add", 0, "")
+	flags.StringVar(&opts.file, "file", "", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	s, err := formatDate(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(s)
+	return nil
+}
+
+func formatDate(opts dateOptions) (string, error) {
+	t, err := dateOf(opts.file)
+	if err != nil {
+		return "", err
+	}
+	t = t.Add(opts.add)
+	if opts.unix {
+		return strconv.FormatInt(t.Unix(), 10), nil
+	}
+	if opts.utc {
+		t = t.UTC()
+	}
+	if strings.ContainsRune(opts.format, '%') {
+		return strftime(t, opts.format), nil
+	}
+	return t.Format(opts.format), nil
+}
+
+// dateOf returns the modification time of file if given, otherwise
+// SOURCE_DATE_EPOCH in UTC if set, otherwise the current local time.
+func dateOf(file string) (time.Time, error) {
+	if file != "" {
+		file, err := expand(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+	if epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok && epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Invalid SOURCE_DATE_EPOCH [%v]", epoch)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Now(), nil
+}
+
+func strftime(t time.Time, layout string) string {
+	var b strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' || i == len(layout)-1 {
+			b.WriteByte(layout[i])
+			continue
+		}
+		i++
+		switch layout[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'e':
+			fmt.Fprintf(&b, "%2d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'I':
+			fmt.Fprintf(&b, "%02d", (t.Hour()+11)%12+1)
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'b', 'h':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'F':
+			b.WriteString(t.Format("2006-01-02"))
+		case 'T':
+			b.WriteString(t.Format("15:04:05"))
+		case 'D':
+			b.WriteString(t.Format("01/02/06"))
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(layout[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestStrftime(t *testing.T) {
+	date := time.Date(2018, time.March, 7, 15, 4, 5, 0, time.UTC)
+	for layout, expected := range map[string]string{
+		"%Y%m%d":            "20180307",
+		"%F %T":             "2018-03-07 15:04:05",
+		"%y-%b-%e %I%p":     "18-Mar- 7 03PM",
+		"%A %B %j":          "Wednesday March 066",
+		"%s":                "1520435045",
+		"100%% %Q":          "100% %Q",
+		"trailing %":        "trailing %",
+		"%H:%M:%S %Z %z %D": "15:04:05 UTC +0000 03/07/18",
+	} {
+		assert.Equal(t, strftime(date, layout), expected)
+	}
+}
+
+func TestFormatDateWithSourceDateEpoch(t *testing.T) {
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	os.Setenv("SOURCE_DATE_EPOCH", "1520435045")
+
+	s, err := formatDate(dateOptions{format: time.RFC3339})
+	assert.NilError(t, err)
+	assert.Equal(t, s, "2018-03-07T15:04:05Z")
+
+	s, err = formatDate(dateOptions{format: "%F", add: 24 * time.Hour})
+	assert.NilError(t, err)
+	assert.Equal(t, s, "2018-03-08")
+
+	s, err = formatDate(dateOptions{unix: true})
+	assert.NilError(t, err)
+	assert.Equal(t, s, "1520435045")
+
+	os.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err = formatDate(dateOptions{format: time.RFC3339})
+	assert.Error(t, err, "Invalid SOURCE_DATE_EPOCH [yesterday]")
+}
+
+func TestFormatDateOfFile(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo"))
+	defer rootDirectory.Remove()
+
+	file := filepath.Join(rootDirectory.Path(), "foo.txt")
+	mtime := time.Date(2018, time.March, 7, 15, 4, 5, 0, time.UTC)
+	assert.NilError(t, os.Chtimes(file, mtime, mtime))
+
+	s, err := formatDate(dateOptions{utc: true, format: time.RFC3339, file: file})
+	assert.NilError(t, err)
+	assert.Equal(t, s, "2018-03-07T15:04:05Z")
+}
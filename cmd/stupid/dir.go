@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+var dirNames = []string{"config", "cache", "data", "temp", "home", "cwd"}
+
+func dir(args []string) error {
+	flags := newFlagSet("dir")
+	slash := flags.Bool("slash", false, "")
+	ensure := flags.Bool("ensure", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("Expected exactly one directory name among %v", dirNames)
+	}
+	path, err := namedDir(args[0])
+	if err != nil {
+		return err
+	}
+	if *ensure {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return err
+		}
+	}
+	if *slash {
+		path = filepath.ToSlash(path)
+	}
+	fmt.Print(path)
+	return nil
+}
+
+// namedDir resolves one of dirNames, following the XDG base directory
+// specification on Linux and the conventional locations elsewhere.
+func namedDir(name string) (string, error) {
+	switch name {
+	case "home":
+		return homedir.Dir()
+	case "cwd":
+		return os.Getwd()
+	case "temp":
+		return os.TempDir(), nil
+	case "config":
+		switch runtime.GOOS {
+		case "windows":
+			return envDir("APPDATA", "AppData", "Roaming")
+		case "darwin":
+			return envDir("", "Library", "Application Support")
+		}
+		return envDir("XDG_CONFIG_HOME", ".config")
+	case "cache":
+		switch runtime.GOOS {
+		case "windows":
+			return envDir("LOCALAPPDATA", "AppData", "Local")
+		case "darwin":
+			return envDir("", "Library", "Caches")
+		}
+		return envDir("XDG_CACHE_HOME", ".cache")
+	case "data":
+		switch runtime.GOOS {
+		case "windows":
+			return envDir("LOCALAPPDATA", "AppData", "Local")
+		case "darwin":
+			return envDir("", "Library", "Application Support")
+		}
+		return envDir("XDG_DATA_HOME", ".local", "share")
+	}
+	return "", fmt.Errorf("Unknown directory [%v], expected one of %v", name, dirNames)
+}
+
+// envDir returns the value of the variable if it is set to an absolute path,
+// otherwise the fallback elements joined to the home directory.
+func envDir(variable string, fallback ...string) (string, error) {
+	if path := os.Getenv(variable); filepath.IsAbs(path) {
+		return path, nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{home}, fallback...)...), nil
+}
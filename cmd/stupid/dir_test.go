@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestNamedDir(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	dir, err := namedDir("cwd")
+	assert.NilError(t, err)
+	assert.Equal(t, dir, cwd)
+
+	dir, err = namedDir("temp")
+	assert.NilError(t, err)
+	assert.Equal(t, dir, os.TempDir())
+
+	_, err = namedDir("pony")
+	assert.Error(t, err, "Unknown directory [pony], expected one of [config cache data temp home cwd]")
+}
+
+func TestNamedDirWithXDG(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG variables are only used on Linux")
+	}
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", rootDirectory.Path())
+
+	dir, err := namedDir("config")
+	assert.NilError(t, err)
+	assert.Equal(t, dir, rootDirectory.Path())
+
+	path, err := expand("~config/foo/bar.txt")
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join(rootDirectory.Path(), "foo", "bar.txt"))
+
+	os.Setenv("XDG_CONFIG_HOME", "relative")
+	dir, err = namedDir("config")
+	assert.NilError(t, err)
+	assert.Equal(t, filepath.Base(dir), ".config")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// command returns a command inheriting the standard streams of stupid.
+func command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// exitCode converts the error of a finished command into an exitError
+// carrying its exit code, leaving other errors untouched.
+func exitCode(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			if status.Signaled() {
+				return exitError(128 + int(status.Signal()))
+			}
+			return exitError(status.ExitStatus())
+		}
+		return exitError(1)
+	}
+	return err
+}
+
+// signaled reports whether the command was terminated by a signal.
+func signaled(err error) bool {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.Signaled()
+		}
+	}
+	return false
+}
+
+// process is a command running in its own process group, so that it can be
+// stopped along with its children.
+type process struct {
+	cmd  *exec.Cmd
+	done chan error
+}
+
+func startProcess(cmd *exec.Cmd) (*process, error) {
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{cmd: cmd, done: make(chan error, 1)}
+	go func() {
+		p.done <- cmd.Wait()
+	}()
+	return p, nil
+}
+
+// stop asks the process group to terminate, killing it if it is still
+// running after the grace period, and returns the result of the command.
+func (p *process) stop(grace time.Duration) error {
+	signalProcessGroup(p.cmd, syscall.SIGTERM)
+	select {
+	case err := <-p.done:
+		return err
+	case <-time.After(grace):
+		signalProcessGroup(p.cmd, syscall.SIGKILL)
+		return <-p.done
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup makes cmd start in a new process group, which contains
+// the processes it starts in turn.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// signalProcessGroup kills the process tree of cmd whatever sig is, console
+// processes having no other way to be asked to terminate.
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
@@ -1,15 +1,164 @@
 package main
 
-import "os/user"
-import "path/filepath"
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+type expansion int
+
+const (
+	expandHome expansion = iota
+	expandEnv
+	expandStrict
+	expandNone
+)
+
+// expandMode is set once from the global options before running a command.
+var expandMode = expandHome
+
+func parseExpansion(mode string) (expansion, error) {
+	switch mode {
+	case "", "home":
+		return expandHome, nil
+	case "env":
+		return expandEnv, nil
+	case "strict":
+		return expandStrict, nil
+	case "none":
+		return expandNone, nil
+	}
+	return expandHome, fmt.Errorf("Unknown expansion mode [%v], expected one of [home env strict none]", mode)
+}
 
 func expand(path string) (string, error) {
+	if expandMode == expandNone {
+		return path, nil
+	}
+	if expandMode == expandEnv || expandMode == expandStrict {
+		var err error
+		path, err = expandVariables(path, expandMode == expandStrict)
+		if err != nil {
+			return "", err
+		}
+	}
 	if len(path) == 0 || path[0] != '~' {
 		return path, nil
 	}
-	usr, err := user.Current()
-	if err != nil {
+	name, rest := path[1:], ""
+	if i := strings.IndexAny(name, "/"+string(os.PathSeparator)); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	if name == "" {
+		name = "home"
+	}
+	for _, dirName := range dirNames {
+		if name == dirName {
+			dir, err := namedDir(name)
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, rest), nil
+		}
+	}
+	usr, err := user.Lookup(name)
+	if _, unknown := err.(user.UnknownUserError); unknown {
+		// Not a user, e.g. a file named ~backup, keep it as is.
+		return path, nil
+	} else if err != nil {
 		return "", err
 	}
-	return filepath.Join(usr.HomeDir, path[1:]), nil
+	return filepath.Join(usr.HomeDir, rest), nil
+}
+
+// expandVariables replaces $VAR, ${VAR}, ${VAR:-default} and %VAR% with the
+// values of the environment variables. Undefined variables are replaced by
+// an empty string unless strict is set, in which case they are an error.
+func expandVariables(s string, strict bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '$' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		name, fallback, hasFallback, n := scanVariable(s[i:])
+		if n == 0 {
+			b.WriteByte(c)
+			continue
+		}
+		i += n - 1
+		value, ok := os.LookupEnv(name)
+		if hasFallback && value == "" {
+			var err error
+			if value, err = expandVariables(fallback, strict); err != nil {
+				return "", err
+			}
+		} else if !ok && strict {
+			return "", fmt.Errorf("Undefined variable [%v]", name)
+		}
+		b.WriteString(value)
+	}
+	return b.String(), nil
+}
+
+// scanVariable parses the variable reference at the start of s and returns
+// its name, its default value if any and its length, zero if s does not
+// start with a variable reference.
+func scanVariable(s string) (name, fallback string, hasFallback bool, n int) {
+	switch {
+	case s[0] == '%':
+		end := strings.IndexByte(s[1:], '%')
+		if end <= 0 || variableNameLength(s[1:]) != end {
+			return "", "", false, 0
+		}
+		return s[1 : end+1], "", false, end + 2
+	case strings.HasPrefix(s, "${"):
+		end := closingBrace(s)
+		if end < 0 {
+			return "", "", false, 0
+		}
+		name = s[2:end]
+		if i := strings.Index(name, ":-"); i >= 0 {
+			name, fallback, hasFallback = name[:i], name[i+2:], true
+		}
+		if name == "" || variableNameLength(name) != len(name) {
+			return "", "", false, 0
+		}
+		return name, fallback, hasFallback, end + 1
+	}
+	l := variableNameLength(s[1:])
+	if l == 0 {
+		return "", "", false, 0
+	}
+	return s[1 : l+1], "", false, l + 1
+}
+
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func variableNameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			continue
+		}
+		return i
+	}
+	return len(s)
 }
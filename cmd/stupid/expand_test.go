@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"gotest.tools/assert"
+)
+
+func TestExpandVariables(t *testing.T) {
+	defer os.Unsetenv("STUPID_FOO")
+	defer os.Unsetenv("STUPID_EMPTY")
+	os.Setenv("STUPID_FOO", "foo")
+	os.Setenv("STUPID_EMPTY", "")
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "$STUPID_FOO/bin", expected: "foo/bin"},
+		{path: "${STUPID_FOO}bar", expected: "foobar"},
+		{path: "%STUPID_FOO%/bin", expected: "foo/bin"},
+		{path: "${STUPID_UNDEFINED:-default}/bin", expected: "default/bin"},
+		{path: "${STUPID_EMPTY:-default}/bin", expected: "default/bin"},
+		{path: "${STUPID_FOO:-default}/bin", expected: "foo/bin"},
+		{path: "${STUPID_UNDEFINED:-${STUPID_FOO}}/bin", expected: "foo/bin"},
+		{path: "$STUPID_UNDEFINED/bin", expected: "/bin"},
+		{path: "100%/$/${/%STUPID FOO%", expected: "100%/$/${/%STUPID FOO%"},
+	} {
+		actual, err := expandVariables(tc.path, false)
+		assert.NilError(t, err)
+		assert.Equal(t, actual, tc.expected, tc.path)
+	}
+}
+
+func TestExpandVariablesStrict(t *testing.T) {
+	_, err := expandVariables("$STUPID_UNDEFINED/bin", true)
+	assert.Error(t, err, "Undefined variable [STUPID_UNDEFINED]")
+
+	actual, err := expandVariables("${STUPID_UNDEFINED:-default}/bin", true)
+	assert.NilError(t, err)
+	assert.Equal(t, actual, "default/bin")
+}
+
+func TestExpandModes(t *testing.T) {
+	defer func() { expandMode = expandHome }()
+	defer os.Unsetenv("STUPID_FOO")
+	os.Setenv("STUPID_FOO", "foo")
+
+	actual, err := expand("$STUPID_FOO/bin")
+	assert.NilError(t, err)
+	assert.Equal(t, actual, "$STUPID_FOO/bin")
+
+	expandMode = expandEnv
+	actual, err = expand("$STUPID_FOO/bin")
+	assert.NilError(t, err)
+	assert.Equal(t, actual, "foo/bin")
+
+	expandMode = expandNone
+	actual, err = expand("~/$STUPID_FOO")
+	assert.NilError(t, err)
+	assert.Equal(t, actual, "~/$STUPID_FOO")
+}
+
+func TestExpandTilde(t *testing.T) {
+	home, err := homedir.Dir()
+	assert.NilError(t, err)
+	usr, err := user.Current()
+	assert.NilError(t, err)
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: ""},
+		{path: "~", expected: home},
+		{path: "~/", expected: home},
+		{path: "~/foo/bar", expected: filepath.Join(home, "foo", "bar")},
+		{path: "~" + string(os.PathSeparator) + "foo", expected: filepath.Join(home, "foo")},
+		{path: "~home/foo", expected: filepath.Join(home, "foo")},
+		{path: "~" + usr.Username, expected: usr.HomeDir},
+		{path: "~" + usr.Username + "/foo", expected: filepath.Join(usr.HomeDir, "foo")},
+		{path: "~no-such-stupid-user/foo", expected: "~no-such-stupid-user/foo"},
+		{path: "~no-such-stupid-user", expected: "~no-such-stupid-user"},
+		{path: "foo/~/bar", expected: "foo/~/bar"},
+		{path: "foo~", expected: "foo~"},
+	} {
+		actual, err := expand(tc.path)
+		assert.NilError(t, err)
+		assert.Equal(t, actual, tc.expected, tc.path)
+	}
+}
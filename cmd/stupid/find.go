@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type findOptions struct {
+	names    stringsFlag
+	excludes stringsFlag
+	kind     string
+	newer    string
+	size     string
+	maxDepth int
+}
+
+func find(args []string) error {
+	var opts findOptions
+	flags := newFlagSet("find")
+	flags.Var(&opts.names, "name", "")
+	flags.Var(&opts.excludes, "exclude", "")
+	flags.StringVar(&opts.kind, "type", "", "")
+	flags.StringVar(&opts.newer, "newer", "", "")
+	flags.StringVar(&opts.size, "size", "", "")
+	flags.IntVar(&opts.maxDepth, "maxdepth", -1, "")
+	print0 := flags.Bool("print0", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	paths, err := findFiles(args, opts)
+	separator := "\n"
+	if *print0 {
+		separator = "\x00"
+	}
+	for _, p := range paths {
+		fmt.Print(p, separator)
+	}
+	return err
+}
+
+// findFiles walks the roots and returns the sorted paths, with forward
+// slashes and prefixed by their root as given, matching all the predicates
+// of opts. Paths which cannot be read are reported to stderr and skipped,
+// the other paths being returned along with exitError(1).
+func findFiles(roots []string, opts findOptions) ([]string, error) {
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	roots, err := glob(roots, true)
+	if err != nil {
+		return nil, err
+	}
+	match, err := findPredicate(opts)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	failed := false
+	for _, root := range roots {
+		prefix := strings.TrimSuffix(filepath.ToSlash(root), "/")
+		err := filepath.Walk(root, func(p string, info os.FileInfo, walkErr error) error {
+			if walkErr != nil {
+				fmt.Fprintln(os.Stderr, walkErr)
+				failed = true
+				if info == nil || !info.IsDir() {
+					return nil
+				}
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			depth := 0
+			p = filepath.ToSlash(root)
+			if rel != "." {
+				p = prefix + "/" + rel
+				depth = strings.Count(rel, "/") + 1
+				if excluded(opts.excludes, rel, info.Name()) {
+					if info.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+			}
+			// Depending on the version of Go, an unreadable directory is
+			// visited either before or only along with its error.
+			visited := walkErr != nil && len(paths) > 0 && paths[len(paths)-1] == p
+			if !visited && match(info) {
+				paths = append(paths, p)
+			}
+			if walkErr != nil || info.IsDir() && opts.maxDepth >= 0 && depth >= opts.maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+	if failed {
+		return paths, exitError(1)
+	}
+	return paths, nil
+}
+
+// excluded reports whether any of the patterns matches either the base name
+// or the path relative to the root.
+func excluded(patterns []string, rel, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func findPredicate(opts findOptions) (func(os.FileInfo) bool, error) {
+	for _, name := range opts.names {
+		if _, err := path.Match(name, ""); err != nil {
+			return nil, fmt.Errorf("Invalid name pattern [%v]", name)
+		}
+	}
+	switch opts.kind {
+	case "", "f", "d", "l":
+	default:
+		return nil, fmt.Errorf("Unknown type [%v], expected one of [f d l]", opts.kind)
+	}
+	var newer time.Time
+	if opts.newer != "" {
+		var err error
+		if newer, err = dateOf(opts.newer); err != nil {
+			return nil, err
+		}
+	}
+	compareSize, err := parseSize(opts.size)
+	if err != nil {
+		return nil, err
+	}
+	return func(info os.FileInfo) bool {
+		if len(opts.names) > 0 {
+			found := false
+			for _, name := range opts.names {
+				if ok, _ := path.Match(name, info.Name()); ok {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		switch opts.kind {
+		case "f":
+			if !info.Mode().IsRegular() {
+				return false
+			}
+		case "d":
+			if !info.IsDir() {
+				return false
+			}
+		case "l":
+			if info.Mode()&os.ModeSymlink == 0 {
+				return false
+			}
+		}
+		if opts.newer != "" && !info.ModTime().After(newer) {
+			return false
+		}
+		return compareSize(info.Size())
+	}, nil
+}
+
+// parseSize parses a size predicate such as +1M, -10k or 512, meaning
+// respectively more than, less than and exactly that number of bytes.
+func parseSize(size string) (func(int64) bool, error) {
+	if size == "" {
+		return func(int64) bool { return true }, nil
+	}
+	sign, digits := byte(0), size
+	if size[0] == '+' || size[0] == '-' {
+		sign, digits = size[0], size[1:]
+	}
+	unit := int64(1)
+	if digits != "" {
+		switch digits[len(digits)-1] {
+		case 'c':
+			unit = 1
+		case 'k', 'K':
+			unit = 1 << 10
+		case 'M':
+			unit = 1 << 20
+		case 'G':
+			unit = 1 << 30
+		}
+		if unit > 1 || digits[len(digits)-1] == 'c' {
+			digits = digits[:len(digits)-1]
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid size [%v]", size)
+	}
+	n *= unit
+	switch sign {
+	case '+':
+		return func(s int64) bool { return s > n }, nil
+	case '-':
+		return func(s int64) bool { return s < n }, nil
+	}
+	return func(s int64) bool { return s == n }, nil
+}
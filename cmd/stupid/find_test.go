@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestFindFiles(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("main.go", "package main\n"),
+		fs.WithFile("README.md", ""),
+		fs.WithDir("pkg",
+			fs.WithFile("b.go", "package pkg\n"),
+			fs.WithFile("a.go", "package pkg\n"),
+			fs.WithDir("deep",
+				fs.WithFile("c.go", ""))),
+		fs.WithDir("vendor",
+			fs.WithFile("v.go", "")))
+	defer rootDirectory.Remove()
+
+	root := filepath.ToSlash(rootDirectory.Path())
+	for _, tc := range []struct {
+		name     string
+		opts     findOptions
+		expected []string
+	}{
+		{
+			name: "all",
+			opts: findOptions{maxDepth: -1},
+			expected: []string{root, root + "/README.md", root + "/main.go", root + "/pkg", root + "/pkg/a.go",
+				root + "/pkg/b.go", root + "/pkg/deep", root + "/pkg/deep/c.go", root + "/vendor", root + "/vendor/v.go"},
+		},
+		{
+			name:     "name and exclude",
+			opts:     findOptions{names: []string{"*.go"}, excludes: []string{"vendor"}, maxDepth: -1},
+			expected: []string{root + "/main.go", root + "/pkg/a.go", root + "/pkg/b.go", root + "/pkg/deep/c.go"},
+		},
+		{
+			name:     "exclude relative path",
+			opts:     findOptions{names: []string{"*.go"}, excludes: []string{"pkg/deep"}, kind: "f", maxDepth: -1},
+			expected: []string{root + "/main.go", root + "/pkg/a.go", root + "/pkg/b.go", root + "/vendor/v.go"},
+		},
+		{
+			name:     "directories up to depth 1",
+			opts:     findOptions{kind: "d", maxDepth: 1},
+			expected: []string{root, root + "/pkg", root + "/vendor"},
+		},
+		{
+			name:     "size",
+			opts:     findOptions{kind: "f", size: "+0", maxDepth: -1},
+			expected: []string{root + "/main.go", root + "/pkg/a.go", root + "/pkg/b.go"},
+		},
+		{
+			name:     "exact size",
+			opts:     findOptions{size: "13c", maxDepth: -1},
+			expected: []string{root + "/main.go"},
+		},
+	} {
+		paths, err := findFiles([]string{rootDirectory.Path()}, tc.opts)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, paths, tc.expected)
+	}
+}
+
+func TestFindKeepsRootPrefix(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("src",
+			fs.WithFile("main.go", "")))
+	defer rootDirectory.Remove()
+
+	root := filepath.ToSlash(rootDirectory.Path())
+	paths, err := findFiles([]string{rootDirectory.Path() + "/./src", rootDirectory.Path() + "/src/"}, findOptions{maxDepth: -1})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths, []string{root + "/./src", root + "/./src/main.go", root + "/src/", root + "/src/main.go"})
+}
+
+func TestFindSkipsUnreadableDirectories(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("locked",
+			fs.WithFile("secret.txt", "")),
+		fs.WithFile("visible.txt", ""))
+	defer rootDirectory.Remove()
+	assert.NilError(t, os.Chmod(rootDirectory.Join("locked"), 0))
+	defer os.Chmod(rootDirectory.Join("locked"), 0755)
+	if _, err := ioutil.ReadDir(rootDirectory.Join("locked")); err == nil {
+		t.Skip("unreadable directories are readable by the current user")
+	}
+
+	root := filepath.ToSlash(rootDirectory.Path())
+	paths, err := findFiles([]string{rootDirectory.Path()}, findOptions{maxDepth: -1})
+	assert.Equal(t, err, exitError(1))
+	assert.DeepEqual(t, paths, []string{root, root + "/locked", root + "/visible.txt"})
+}
+
+func TestFindNewer(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("old.txt", ""),
+		fs.WithFile("reference", ""),
+		fs.WithFile("new.txt", ""))
+	defer rootDirectory.Remove()
+
+	now := time.Now()
+	for name, mtime := range map[string]time.Time{
+		"old.txt":   now.Add(-2 * time.Hour),
+		"reference": now.Add(-time.Hour),
+		"new.txt":   now,
+	} {
+		assert.NilError(t, os.Chtimes(filepath.Join(rootDirectory.Path(), name), mtime, mtime))
+	}
+
+	paths, err := findFiles([]string{rootDirectory.Path()}, findOptions{
+		kind:     "f",
+		newer:    filepath.Join(rootDirectory.Path(), "reference"),
+		maxDepth: -1,
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths, []string{filepath.ToSlash(rootDirectory.Path()) + "/new.txt"})
+}
+
+func TestParseSize(t *testing.T) {
+	for _, tc := range []struct {
+		size     string
+		value    int64
+		expected bool
+	}{
+		{size: "+1M", value: 1 << 20, expected: false},
+		{size: "+1M", value: 1<<20 + 1, expected: true},
+		{size: "-1k", value: 1023, expected: true},
+		{size: "-1k", value: 1024, expected: false},
+		{size: "2G", value: 2 << 30, expected: true},
+	} {
+		compare, err := parseSize(tc.size)
+		assert.NilError(t, err)
+		assert.Equal(t, compare(tc.value), tc.expected, tc.size)
+	}
+	_, err := parseSize("+1X")
+	assert.Error(t, err, "Invalid size [+1X]")
+}
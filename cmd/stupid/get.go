@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func get(args []string) error {
+	flags := newFlagSet("get")
+	fallback := flags.String("default", "", "")
+	raw := flags.Bool("raw", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("Expected a file and a path")
+	}
+	file, path := args[0], args[1]
+	keys, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	data, err := readData(file)
+	if err != nil {
+		return err
+	}
+	value, ok := lookupPath(data, keys)
+	if !ok {
+		hasDefault := false
+		flags.Visit(func(f *flag.Flag) {
+			hasDefault = hasDefault || f.Name == "default"
+		})
+		if hasDefault {
+			fmt.Print(*fallback)
+			return nil
+		}
+		return fmt.Errorf("Path [%v] not found in [%v]", path, file)
+	}
+	output, err := formatValue(value, *raw)
+	if err != nil {
+		return err
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// parsePath splits a path such as .servers[0].host or ."dotted.key" into
+// string keys and integer indexes.
+func parsePath(path string) ([]interface{}, error) {
+	var keys []interface{}
+	rest := strings.TrimPrefix(path, ".")
+	for first := true; rest != ""; first = false {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if strings.HasPrefix(rest, `["`) {
+				if end = closingQuote(rest[1:]) + 2; end == 1 || end >= len(rest) || rest[end] != ']' {
+					return nil, fmt.Errorf("Invalid path [%v]", path)
+				}
+				key, err := strconv.Unquote(rest[1:end])
+				if err != nil {
+					return nil, fmt.Errorf("Invalid path [%v]", path)
+				}
+				keys = append(keys, key)
+			} else {
+				if end < 0 {
+					return nil, fmt.Errorf("Invalid path [%v]", path)
+				}
+				index, err := strconv.Atoi(rest[1:end])
+				if err != nil {
+					return nil, fmt.Errorf("Invalid path [%v]", path)
+				}
+				keys = append(keys, index)
+			}
+			rest = rest[end+1:]
+		case first || rest[0] == '.':
+			if !first {
+				rest = rest[1:]
+			}
+			if strings.HasPrefix(rest, `"`) {
+				end := closingQuote(rest)
+				key, err := strconv.Unquote(rest[:end+1])
+				if end < 0 || err != nil {
+					return nil, fmt.Errorf("Invalid path [%v]", path)
+				}
+				keys = append(keys, key)
+				rest = rest[end+1:]
+				continue
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("Invalid path [%v]", path)
+			}
+			keys = append(keys, rest[:end])
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("Invalid path [%v]", path)
+		}
+	}
+	return keys, nil
+}
+
+// lookupPath returns the value at keys, where negative indexes count from
+// the end of lists, which can also be indexed by numeric keys.
+func lookupPath(data interface{}, keys []interface{}) (interface{}, bool) {
+	for _, key := range keys {
+		switch values := data.(type) {
+		case map[string]interface{}:
+			name, ok := key.(string)
+			if !ok {
+				return nil, false
+			}
+			if data, ok = values[name]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			index, ok := key.(int)
+			if name, isName := key.(string); isName {
+				var err error
+				index, err = strconv.Atoi(name)
+				ok = err == nil
+			}
+			if index < 0 {
+				index += len(values)
+			}
+			if !ok || index < 0 || index >= len(values) {
+				return nil, false
+			}
+			data = values[index]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// formatValue encodes value as indented JSON, except for strings which are
+// printed as is when raw is set.
+func formatValue(value interface{}, raw bool) (string, error) {
+	if s, ok := value.(string); ok && raw {
+		return s, nil
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected []interface{}
+	}{
+		{path: ".", expected: nil},
+		{path: ".version", expected: []interface{}{"version"}},
+		{path: "servers[0].host", expected: []interface{}{"servers", 0, "host"}},
+		{path: ".servers[-1]", expected: []interface{}{"servers", -1}},
+		{path: `.scripts."build.prod"`, expected: []interface{}{"scripts", "build.prod"}},
+		{path: `["a]b"][1][2]`, expected: []interface{}{"a]b", 1, 2}},
+		{path: ".a.0", expected: []interface{}{"a", "0"}},
+		{path: "devDependencies.@types/node", expected: []interface{}{"devDependencies", "@types/node"}},
+	} {
+		keys, err := parsePath(tc.path)
+		assert.NilError(t, err, tc.path)
+		assert.DeepEqual(t, keys, tc.expected)
+	}
+	for _, path := range []string{"..a", "a.", "a[", "a[x]", `a."b`, `a["b]`} {
+		_, err := parsePath(path)
+		assert.Error(t, err, "Invalid path ["+path+"]", path)
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	data := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "port": nil},
+			map[string]interface{}{"host": "b"},
+		},
+	}
+	for _, tc := range []struct {
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{path: "servers[0].host", expected: "a", found: true},
+		{path: "servers.1.host", expected: "b", found: true},
+		{path: "servers[-1].host", expected: "b", found: true},
+		{path: "servers[0].port", expected: nil, found: true},
+		{path: "servers[2].host", found: false},
+		{path: "servers[-3]", found: false},
+		{path: "servers.host", found: false},
+		{path: "servers[0].host.name", found: false},
+		{path: "missing", found: false},
+	} {
+		keys, err := parsePath(tc.path)
+		assert.NilError(t, err)
+		value, found := lookupPath(data, keys)
+		assert.Equal(t, found, tc.found, tc.path)
+		assert.DeepEqual(t, value, tc.expected)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		raw      bool
+		expected string
+	}{
+		{value: "1.2.0", expected: `"1.2.0"`},
+		{value: "1.2.0", raw: true, expected: "1.2.0"},
+		{value: "<a & b>", raw: false, expected: `"<a & b>"`},
+		{value: json.Number("5432"), raw: true, expected: "5432"},
+		{value: int64(1), expected: "1"},
+		{value: nil, raw: true, expected: "null"},
+		{value: map[string]interface{}{"b": []interface{}{true}, "a": 1.5}, raw: true, expected: "{\n  \"a\": 1.5,\n  \"b\": [\n    true\n  ]\n}"},
+	} {
+		output, err := formatValue(tc.value, tc.raw)
+		assert.NilError(t, err)
+		assert.Equal(t, output, tc.expected)
+	}
+}
+
+func TestReadDataFormats(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("package.json", `{"version": "1.2.0"}`),
+		fs.WithFile("Cargo.toml", "[package]\nversion = \"1.2.0\"\n"),
+		fs.WithFile("config.yml", "version: 1.2.0\n"),
+		fs.WithFile("json.lock", " {\"version\": \"1.2.0\"}\n"),
+		fs.WithFile("toml.lock", "# comment\n\n[package]\nversion = \"1.2.0\"\n"),
+		fs.WithFile("toml.conf", "version = \"1.2.0\"\n"),
+		fs.WithFile("yaml.conf", "version: 1.2.0\n"),
+		fs.WithFile("list.conf", "[a, b]\n"),
+		fs.WithFile("broken.json", `{"version": `))
+	defer rootDirectory.Remove()
+
+	for _, tc := range []struct {
+		file string
+		path string
+	}{
+		{file: "package.json", path: ".version"},
+		{file: "Cargo.toml", path: ".package.version"},
+		{file: "config.yml", path: ".version"},
+		{file: "json.lock", path: ".version"},
+		{file: "toml.lock", path: ".package.version"},
+		{file: "toml.conf", path: ".version"},
+		{file: "yaml.conf", path: ".version"},
+	} {
+		data, err := readData(rootDirectory.Join(tc.file))
+		assert.NilError(t, err, tc.file)
+		keys, err := parsePath(tc.path)
+		assert.NilError(t, err)
+		value, found := lookupPath(data, keys)
+		assert.Assert(t, found, tc.file)
+		assert.Equal(t, value, "1.2.0", tc.file)
+	}
+
+	_, err := readData(rootDirectory.Join("list.conf"))
+	assert.Error(t, err, "Unknown format of ["+rootDirectory.Join("list.conf")+"], expected one of [json yaml toml env]")
+	_, err = readData(rootDirectory.Join("broken.json"))
+	assert.ErrorContains(t, err, "Cannot read ["+rootDirectory.Join("broken.json")+"]")
+}
@@ -0,0 +1,705 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// gitRepository reads a git repository directly from its .git directory,
+// without requiring the git binary.
+type gitRepository struct {
+	worktree string
+	// gitDir holds HEAD and the index, commonDir the objects and the refs,
+	// they only differ for additional worktrees.
+	gitDir    string
+	commonDir string
+	packs     []*gitPack
+	parents   map[string][]string
+	// shallow holds the commits of a shallow clone whose parents are missing.
+	shallow map[string]bool
+}
+
+type gitPack struct {
+	path string
+	idx  []byte
+	n    int
+	// entries caches the objects read by offset, which are read repeatedly
+	// when walking the history or as delta bases.
+	entries map[int64]gitObject
+}
+
+type gitObject struct {
+	kind int
+	data []byte
+}
+
+// gitObjectNotFound is the error returned when an object is missing, e.g.
+// beyond the history of a shallow clone.
+type gitObjectNotFound string
+
+func (e gitObjectNotFound) Error() string {
+	return fmt.Sprintf("Git object [%v] not found", string(e))
+}
+
+const (
+	gitCommit   = 1
+	gitTree     = 2
+	gitBlob     = 3
+	gitTag      = 4
+	gitOfsDelta = 6
+	gitRefDelta = 7
+)
+
+var gitObjectTypes = map[string]int{"commit": gitCommit, "tree": gitTree, "blob": gitBlob, "tag": gitTag}
+
+// openGitRepository opens the repository containing path.
+func openGitRepository(path string) (*gitRepository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if !info.IsDir() {
+				// A worktree or a submodule, .git points to the actual directory.
+				content, err := ioutil.ReadFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+				line := strings.TrimSpace(string(content))
+				if !strings.HasPrefix(line, "gitdir:") {
+					return nil, fmt.Errorf("Invalid git file [%v]", dotGit)
+				}
+				dotGit = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+				if !filepath.IsAbs(dotGit) {
+					dotGit = filepath.Join(dir, dotGit)
+				}
+			}
+			return newGitRepository(dir, dotGit)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("No git repository found in [%v] or its parents", path)
+		}
+		dir = parent
+	}
+}
+
+func newGitRepository(worktree, gitDir string) (*gitRepository, error) {
+	r := &gitRepository{worktree: worktree, gitDir: gitDir, commonDir: gitDir, parents: map[string][]string{}, shallow: map[string]bool{}}
+	if content, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		r.commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(r.commonDir) {
+			r.commonDir = filepath.Join(gitDir, r.commonDir)
+		}
+	}
+	content, err := ioutil.ReadFile(filepath.Join(r.commonDir, "shallow"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, sha := range strings.Fields(string(content)) {
+		r.shallow[sha] = true
+	}
+	idxs, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range idxs {
+		content, err := ioutil.ReadFile(idx)
+		if err != nil {
+			return nil, err
+		}
+		if len(content) < 8+256*4 || !bytes.Equal(content[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+			return nil, fmt.Errorf("Unsupported pack index [%v]", idx)
+		}
+		n := int(binary.BigEndian.Uint32(content[8+255*4:]))
+		r.packs = append(r.packs, &gitPack{path: strings.TrimSuffix(idx, ".idx") + ".pack", idx: content, n: n, entries: map[int64]gitObject{}})
+	}
+	return r, nil
+}
+
+// head returns the commit checked out.
+func (r *gitRepository) head() (string, error) {
+	return r.resolve("HEAD")
+}
+
+// resolve follows symbolic references until an object name is found.
+func (r *gitRepository) resolve(ref string) (string, error) {
+	for i := 0; i < 10; i++ {
+		dir := r.commonDir
+		if ref == "HEAD" {
+			dir = r.gitDir
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if os.IsNotExist(err) {
+			refs, err := r.packedRefs()
+			if err != nil {
+				return "", err
+			}
+			if sha, ok := refs[ref]; ok {
+				return sha, nil
+			}
+			return "", fmt.Errorf("Unknown git reference [%v]", ref)
+		} else if err != nil {
+			return "", err
+		}
+		line := strings.TrimSpace(string(content))
+		if !strings.HasPrefix(line, "ref:") {
+			return line, nil
+		}
+		ref = strings.TrimSpace(strings.TrimPrefix(line, "ref:"))
+	}
+	return "", fmt.Errorf("Too many levels of symbolic git references")
+}
+
+func (r *gitRepository) packedRefs() (map[string]string, error) {
+	refs := map[string]string{}
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return refs, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	return refs, scanner.Err()
+}
+
+// refs returns the references starting with prefix, e.g. refs/tags/,
+// loose references taking precedence over packed ones.
+func (r *gitRepository) refs(prefix string) (map[string]string, error) {
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]string{}
+	for name, sha := range packed {
+		if strings.HasPrefix(name, prefix) {
+			refs[name] = sha
+		}
+	}
+	root := filepath.Join(r.commonDir, filepath.FromSlash(prefix))
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(r.commonDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		sha, err := r.resolve(name)
+		if err != nil {
+			return err
+		}
+		refs[name] = sha
+		return nil
+	})
+	return refs, err
+}
+
+// peel dereferences annotated tags until a non tag object is found.
+func (r *gitRepository) peel(sha string) (string, error) {
+	for {
+		kind, data, err := r.readObject(sha)
+		if err != nil {
+			return "", err
+		}
+		if kind != gitTag {
+			return sha, nil
+		}
+		target := headerField(data, "object")
+		if target == "" {
+			return "", fmt.Errorf("Invalid git tag [%v]", sha)
+		}
+		sha = target
+	}
+}
+
+// commitParents returns the parents of a commit, the commits listed as
+// shallow having none.
+func (r *gitRepository) commitParents(sha string) ([]string, error) {
+	if parents, ok := r.parents[sha]; ok {
+		return parents, nil
+	}
+	if r.shallow[sha] {
+		r.parents[sha] = nil
+		return nil, nil
+	}
+	kind, data, err := r.readObject(sha)
+	if err != nil {
+		return nil, err
+	}
+	if kind != gitCommit {
+		return nil, fmt.Errorf("Git object [%v] is not a commit", sha)
+	}
+	var parents []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "parent ") {
+			parents = append(parents, strings.TrimPrefix(line, "parent "))
+		}
+	}
+	r.parents[sha] = parents
+	return parents, nil
+}
+
+// ancestors returns the commits reachable from sha, including itself.
+func (r *gitRepository) ancestors(sha string) (map[string]bool, error) {
+	seen := map[string]bool{sha: true}
+	queue := []string{sha}
+	for len(queue) > 0 {
+		parents, err := r.commitParents(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, parent := range parents {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return seen, nil
+}
+
+// tree collects the entries of a tree, or of the tree of a commit,
+// recursively into entries by path.
+func (r *gitRepository) tree(sha string, prefix string, entries map[string]gitEntry) error {
+	kind, data, err := r.readObject(sha)
+	if err != nil {
+		return err
+	}
+	if kind == gitCommit {
+		return r.tree(headerField(data, "tree"), prefix, entries)
+	}
+	if kind != gitTree {
+		return fmt.Errorf("Git object [%v] is not a tree", sha)
+	}
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return fmt.Errorf("Invalid git tree [%v]", sha)
+		}
+		var mode uint32
+		fmt.Sscanf(string(data[:space]), "%o", &mode)
+		name := prefix + string(data[space+1:nul])
+		entry := gitEntry{mode: mode, sha: hex.EncodeToString(data[nul+1 : nul+21])}
+		data = data[nul+21:]
+		if mode == 040000 {
+			if err := r.tree(entry.sha, name+"/", entries); err != nil {
+				return err
+			}
+			continue
+		}
+		entries[name] = entry
+	}
+	return nil
+}
+
+type gitEntry struct {
+	mode uint32
+	sha  string
+}
+
+func headerField(data []byte, name string) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, name+" ") {
+			return strings.TrimPrefix(line, name+" ")
+		}
+	}
+	return ""
+}
+
+// readObject returns the type and the content of an object, either loose or
+// packed.
+func (r *gitRepository) readObject(sha string) (int, []byte, error) {
+	if len(sha) != 40 {
+		return 0, nil, fmt.Errorf("Invalid git object name [%v]", sha)
+	}
+	f, err := os.Open(filepath.Join(r.commonDir, "objects", sha[:2], sha[2:]))
+	if os.IsNotExist(err) {
+		return r.readPackedObject(sha)
+	} else if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	z, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer z.Close()
+	content, err := ioutil.ReadAll(z)
+	if err != nil {
+		return 0, nil, err
+	}
+	nul := bytes.IndexByte(content, 0)
+	space := bytes.IndexByte(content, ' ')
+	if nul < 0 || space < 0 || space > nul {
+		return 0, nil, fmt.Errorf("Invalid git object [%v]", sha)
+	}
+	kind, ok := gitObjectTypes[string(content[:space])]
+	if !ok {
+		return 0, nil, fmt.Errorf("Invalid git object [%v]", sha)
+	}
+	return kind, content[nul+1:], nil
+}
+
+func (r *gitRepository) readPackedObject(sha string) (int, []byte, error) {
+	raw, err := hex.DecodeString(sha)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Invalid git object name [%v]", sha)
+	}
+	for _, pack := range r.packs {
+		if offset, ok := pack.find(raw); ok {
+			return r.readPackEntry(pack, offset)
+		}
+	}
+	return 0, nil, gitObjectNotFound(sha)
+}
+
+// find looks up the offset of an object in the pack using its index.
+func (p *gitPack) find(sha []byte) (int64, bool) {
+	const fanout = 8
+	names := fanout + 256*4
+	lo := 0
+	if sha[0] > 0 {
+		lo = int(binary.BigEndian.Uint32(p.idx[fanout+(int(sha[0])-1)*4:]))
+	}
+	hi := int(binary.BigEndian.Uint32(p.idx[fanout+int(sha[0])*4:]))
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.idx[names+(lo+i)*20:names+(lo+i+1)*20], sha) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.idx[names+i*20:names+(i+1)*20], sha) {
+		return 0, false
+	}
+	offsets := names + p.n*20 + p.n*4
+	offset := binary.BigEndian.Uint32(p.idx[offsets+i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	large := offsets + p.n*4 + int(offset&0x7fffffff)*8
+	return int64(binary.BigEndian.Uint64(p.idx[large:])), true
+}
+
+func (r *gitRepository) readPackEntry(pack *gitPack, offset int64) (int, []byte, error) {
+	if object, ok := pack.entries[offset]; ok {
+		return object.kind, object.data, nil
+	}
+	kind, data, err := r.readUncachedPackEntry(pack, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	pack.entries[offset] = gitObject{kind, data}
+	return kind, data, nil
+}
+
+func (r *gitRepository) readUncachedPackEntry(pack *gitPack, offset int64) (int, []byte, error) {
+	f, err := os.Open(pack.path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+	b, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	kind := int(b>>4) & 7
+	size := int64(b & 15)
+	for shift := uint(4); b&0x80 != 0; shift += 7 {
+		if b, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(b&0x7f) << shift
+	}
+	var baseKind int
+	var base []byte
+	switch kind {
+	case gitOfsDelta:
+		b, err = br.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		distance := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = br.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = (distance+1)<<7 | int64(b&0x7f)
+		}
+		if baseKind, base, err = r.readPackEntry(pack, offset-distance); err != nil {
+			return 0, nil, err
+		}
+	case gitRefDelta:
+		ref := make([]byte, 20)
+		if _, err = io.ReadFull(br, ref); err != nil {
+			return 0, nil, err
+		}
+		if baseKind, base, err = r.readObject(hex.EncodeToString(ref)); err != nil {
+			return 0, nil, err
+		}
+	}
+	z, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer z.Close()
+	data := make([]byte, size)
+	if _, err = io.ReadFull(z, data); err != nil {
+		return 0, nil, err
+	}
+	if base == nil {
+		return kind, data, nil
+	}
+	data, err = applyDelta(base, data)
+	return baseKind, data, err
+}
+
+func applyDelta(base, delta []byte) ([]byte, error) {
+	invalid := fmt.Errorf("Invalid git delta")
+	varint := func() int {
+		n, shift := 0, uint(0)
+		for len(delta) > 0 {
+			b := delta[0]
+			delta = delta[1:]
+			n |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		return n
+	}
+	if varint() != len(base) {
+		return nil, invalid
+	}
+	result := make([]byte, 0, varint())
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			if op == 0 || int(op) > len(delta) {
+				return nil, invalid
+			}
+			result = append(result, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+		var offset, size int
+		for i := uint(0); i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, invalid
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				size |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > len(base) {
+			return nil, invalid
+		}
+		result = append(result, base[offset:offset+size]...)
+	}
+	return result, nil
+}
+
+type gitIndexEntry struct {
+	gitEntry
+	mtime     uint32
+	mtimeNano uint32
+	size      uint32
+	stage     int
+	skip      bool
+}
+
+// index returns the entries of the index by path.
+func (r *gitRepository) index() (map[string]gitIndexEntry, error) {
+	content, err := ioutil.ReadFile(filepath.Join(r.gitDir, "index"))
+	if os.IsNotExist(err) {
+		return map[string]gitIndexEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	invalid := fmt.Errorf("Invalid git index in [%v]", r.gitDir)
+	if len(content) < 12 || string(content[:4]) != "DIRC" {
+		return nil, invalid
+	}
+	version := binary.BigEndian.Uint32(content[4:])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("Unsupported git index version %v", version)
+	}
+	count := int(binary.BigEndian.Uint32(content[8:]))
+	entries := make(map[string]gitIndexEntry, count)
+	data := content[12:]
+	name := ""
+	for i := 0; i < count; i++ {
+		if len(data) < 62 {
+			return nil, invalid
+		}
+		flags := binary.BigEndian.Uint16(data[60:])
+		entry := gitIndexEntry{
+			gitEntry: gitEntry{
+				mode: binary.BigEndian.Uint32(data[24:]),
+				sha:  hex.EncodeToString(data[40:60]),
+			},
+			mtime:     binary.BigEndian.Uint32(data[8:]),
+			mtimeNano: binary.BigEndian.Uint32(data[12:]),
+			size:      binary.BigEndian.Uint32(data[36:]),
+			stage:     int(flags>>12) & 3,
+			skip:      flags&0x8000 != 0,
+		}
+		header := 62
+		if flags&0x4000 != 0 {
+			if version < 3 || len(data) < 64 {
+				return nil, invalid
+			}
+			extended := binary.BigEndian.Uint16(data[62:])
+			entry.skip = entry.skip || extended&0x4000 != 0
+			header = 64
+		}
+		rest := data[header:]
+		if version == 4 {
+			strip, n := 0, 0
+			for {
+				if n >= len(rest) {
+					return nil, invalid
+				}
+				b := rest[n]
+				n++
+				strip = strip<<7 | int(b&0x7f)
+				if b&0x80 == 0 {
+					break
+				}
+				strip++
+			}
+			nul := bytes.IndexByte(rest[n:], 0)
+			if nul < 0 || strip > len(name) {
+				return nil, invalid
+			}
+			name = name[:len(name)-strip] + string(rest[n:n+nul])
+			data = rest[n+nul+1:]
+		} else {
+			nul := bytes.IndexByte(rest, 0)
+			if nul < 0 {
+				return nil, invalid
+			}
+			name = string(rest[:nul])
+			size := (header + nul + 8) &^ 7
+			if len(data) < size {
+				return nil, invalid
+			}
+			data = data[size:]
+		}
+		if previous, ok := entries[name]; ok && previous.stage != 0 {
+			continue
+		}
+		entries[name] = entry
+	}
+	return entries, nil
+}
+
+// dirty reports whether the index or the working tree differ from commit,
+// ignoring untracked files like git describe --dirty.
+func (r *gitRepository) dirty(commit string) (bool, error) {
+	index, err := r.index()
+	if err != nil {
+		return false, err
+	}
+	tree := map[string]gitEntry{}
+	if err := r.tree(commit, "", tree); err != nil {
+		return false, err
+	}
+	if len(tree) != len(index) {
+		return true, nil
+	}
+	for path, entry := range index {
+		if entry.stage != 0 || tree[path] != entry.gitEntry {
+			return true, nil
+		}
+		modified, err := r.modified(path, entry)
+		if err != nil || modified {
+			return modified, err
+		}
+	}
+	return false, nil
+}
+
+// modified reports whether the file in the working tree differs from its
+// index entry.
+func (r *gitRepository) modified(path string, entry gitIndexEntry) (bool, error) {
+	if entry.skip || entry.mode == 0160000 {
+		return false, nil
+	}
+	file := filepath.Join(r.worktree, filepath.FromSlash(path))
+	info, err := os.Lstat(file)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	symlink := info.Mode()&os.ModeSymlink != 0
+	if symlink != (entry.mode == 0120000) || uint32(info.Size()) != entry.size {
+		return true, nil
+	}
+	if runtime.GOOS != "windows" && !symlink && (info.Mode()&0100 != 0) != (entry.mode == 0100755) {
+		return true, nil
+	}
+	mtime := info.ModTime()
+	if uint32(mtime.Unix()) == entry.mtime && uint32(mtime.Nanosecond()) == entry.mtimeNano {
+		return false, nil
+	}
+	var content []byte
+	if symlink {
+		target, err := os.Readlink(file)
+		if err != nil {
+			return false, err
+		}
+		content = []byte(target)
+	} else if content, err = ioutil.ReadFile(file); err != nil {
+		return false, err
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil)) != entry.sha, nil
+}
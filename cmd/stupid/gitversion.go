@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionalString is a string flag which can also be given without a value,
+// like a boolean flag, in which case it takes its fallback value.
+type optionalString struct {
+	value    string
+	fallback string
+}
+
+func (o *optionalString) String() string {
+	return o.value
+}
+
+func (o *optionalString) Set(value string) error {
+	switch value {
+	case "true":
+		o.value = o.fallback
+	case "false":
+		o.value = ""
+	default:
+		o.value = value
+	}
+	return nil
+}
+
+func (o *optionalString) IsBoolFlag() bool {
+	return true
+}
+
+func gitVersion(args []string) error {
+	flags := newFlagSet("gitversion")
+	format := flags.String("format", "describe", "")
+	dirtySuffix := &optionalString{fallback: "-dirty"}
+	flags.Var(dirtySuffix, "dirty-suffix", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("Unexpected arguments %v", args[1:])
+	}
+	path := "."
+	if len(args) == 1 {
+		if path, err = expand(args[0]); err != nil {
+			return err
+		}
+	}
+	description, err := describeGit(path, dirtySuffix.value != "")
+	if err != nil {
+		return err
+	}
+	v, err := description.format(*format, dirtySuffix.value)
+	if err != nil {
+		return err
+	}
+	fmt.Print(v)
+	return nil
+}
+
+type gitDescription struct {
+	// tag is the nearest tag reachable from commit, empty if none.
+	tag string
+	// distance is the number of commits since tag, or since the beginning
+	// of the history if there is no tag.
+	distance int
+	commit   string
+	dirty    bool
+}
+
+// describeGit finds the nearest tag of the commit checked out in the
+// repository containing path, like git describe --tags.
+func describeGit(path string, checkDirty bool) (gitDescription, error) {
+	var d gitDescription
+	repo, err := openGitRepository(path)
+	if err != nil {
+		return d, err
+	}
+	if d.commit, err = repo.head(); err != nil {
+		return d, err
+	}
+	if checkDirty {
+		if d.dirty, err = repo.dirty(d.commit); err != nil {
+			return d, err
+		}
+	}
+	tags, err := repo.refs("refs/tags/")
+	if err != nil {
+		return d, err
+	}
+	tagged := map[string][]string{}
+	for name, sha := range tags {
+		commit, err := repo.peel(sha)
+		if _, missing := err.(gitObjectNotFound); missing {
+			// Like git, ignore the tags beyond the history of a shallow clone.
+			continue
+		} else if err != nil {
+			return d, err
+		}
+		tagged[commit] = append(tagged[commit], strings.TrimPrefix(name, "refs/tags/"))
+	}
+	head, err := repo.ancestors(d.commit)
+	if err != nil {
+		return d, err
+	}
+	d.distance = len(head)
+	queue := []string{d.commit}
+	seen := map[string]bool{d.commit: true}
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+		if names := tagged[commit]; len(names) > 0 {
+			sort.Slice(names, func(i, j int) bool {
+				cmp, err := compareVersions(names[i], names[j])
+				if err != nil {
+					return names[i] > names[j]
+				}
+				return cmp > 0
+			})
+			d.tag = names[0]
+			base, err := repo.ancestors(commit)
+			if err != nil {
+				return d, err
+			}
+			d.distance -= len(base)
+			return d, nil
+		}
+		parents, err := repo.commitParents(commit)
+		if err != nil {
+			return d, err
+		}
+		for _, parent := range parents {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return d, nil
+}
+
+// format formats the description as semver, describe or short, adding
+// dirtySuffix if the working tree is dirty.
+func (d gitDescription) format(format, dirtySuffix string) (string, error) {
+	short := d.commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	suffix := ""
+	if d.dirty {
+		suffix = dirtySuffix
+	}
+	switch format {
+	case "short":
+		return short + suffix, nil
+	case "describe":
+		if d.tag == "" {
+			return short + suffix, nil
+		}
+		if d.distance == 0 {
+			return d.tag + suffix, nil
+		}
+		return fmt.Sprintf("%v-%v-g%v%v", d.tag, d.distance, short, suffix), nil
+	case "semver":
+		return d.semver(short, strings.TrimLeft(suffix, "-+."))
+	}
+	return "", fmt.Errorf("Unknown format [%v], expected one of [semver describe short]", format)
+}
+
+// semver returns the tag without its v prefix, or the next patch version
+// followed by a dev pre-release if there are commits since the tag.
+func (d gitDescription) semver(short, dirty string) (string, error) {
+	tag := d.tag
+	if tag == "" {
+		tag = "0.0.0"
+	}
+	numbers, pre, err := parseVersion(tag)
+	if err != nil {
+		return "", fmt.Errorf("Tag [%v] is not a semantic version", d.tag)
+	}
+	for len(numbers) < 3 {
+		numbers = append(numbers, 0)
+	}
+	var metadata []string
+	if d.distance > 0 {
+		if pre == "" {
+			numbers[2]++
+			pre = "dev." + strconv.Itoa(d.distance)
+		} else {
+			pre += ".dev." + strconv.Itoa(d.distance)
+		}
+		metadata = append(metadata, "g"+short)
+	}
+	if dirty != "" {
+		metadata = append(metadata, dirty)
+	}
+	v := fmt.Sprintf("%d.%d.%d", numbers[0], numbers[1], numbers[2])
+	if pre != "" {
+		v += "-" + pre
+	}
+	if len(metadata) > 0 {
+		v += "+" + strings.Join(metadata, ".")
+	}
+	return v, nil
+}
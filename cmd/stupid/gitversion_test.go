@@ -0,0 +1,160 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+// gitFixture runs git commands in a temporary repository, it is only used
+// to create fixtures and to compare with the output of git describe.
+type gitFixture struct {
+	t   *testing.T
+	dir *fs.Dir
+}
+
+func newGitFixture(t *testing.T) *gitFixture {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is required to create the fixture repositories")
+	}
+	f := &gitFixture{t: t, dir: fs.NewDir(t, "repo")}
+	f.git("init", "-q")
+	return f
+}
+
+func (f *gitFixture) git(args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = f.dir.Path()
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=stupid", "GIT_AUTHOR_EMAIL=stupid@example.com",
+		"GIT_COMMITTER_NAME=stupid", "GIT_COMMITTER_EMAIL=stupid@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+f.dir.Path())
+	out, err := cmd.CombinedOutput()
+	assert.NilError(f.t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func (f *gitFixture) write(name, content string) {
+	path := filepath.Join(f.dir.Path(), name)
+	assert.NilError(f.t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NilError(f.t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func (f *gitFixture) commit(name, content string) {
+	f.write(name, content)
+	f.git("add", name)
+	f.git("commit", "-q", "-m", "Change "+name)
+}
+
+func (f *gitFixture) version(format string, dirty bool) string {
+	d, err := describeGit(filepath.Join(f.dir.Path(), "sub", "dir"), dirty)
+	assert.NilError(f.t, err)
+	suffix := ""
+	if dirty {
+		suffix = "-dirty"
+	}
+	v, err := d.format(format, suffix)
+	assert.NilError(f.t, err)
+	return v
+}
+
+func TestGitVersionWithoutTags(t *testing.T) {
+	f := newGitFixture(t)
+	defer f.dir.Remove()
+	f.commit("foo.txt", "foo")
+	f.commit("sub/dir/bar.txt", "bar")
+
+	short := f.git("rev-parse", "--short=7", "HEAD")
+	assert.Equal(t, f.version("short", false), short)
+	assert.Equal(t, f.version("describe", false), f.git("describe", "--tags", "--always", "--abbrev=7"))
+	assert.Equal(t, f.version("semver", false), "0.0.1-dev.2+g"+short)
+}
+
+func TestGitVersionWithTags(t *testing.T) {
+	f := newGitFixture(t)
+	defer f.dir.Remove()
+	content := strings.Repeat("some long enough content to be deltified\n", 100)
+	f.commit("sub/dir/foo.txt", content)
+	f.git("tag", "v1.1.0")
+	f.commit("sub/dir/foo.txt", content+"more")
+	f.git("tag", "-a", "-m", "Release", "v1.2.0")
+	assert.Equal(t, f.version("describe", false), "v1.2.0")
+	assert.Equal(t, f.version("semver", false), "1.2.0")
+
+	f.git("checkout", "-q", "-b", "feature")
+	f.commit("sub/dir/foo.txt", content+"even more")
+	f.git("checkout", "-q", "-")
+	f.commit("bar.txt", "bar")
+	f.git("merge", "-q", "--no-edit", "feature")
+	expected := f.git("describe", "--tags", "--abbrev=7")
+	short := f.git("rev-parse", "--short=7", "HEAD")
+	assert.Equal(t, expected, "v1.2.0-3-g"+short)
+	assert.Equal(t, f.version("describe", false), expected)
+	assert.Equal(t, f.version("semver", false), "1.2.1-dev.3+g"+short)
+
+	// Packs objects, with deltas, and references.
+	f.git("gc", "-q", "--aggressive")
+	_, err := os.Stat(filepath.Join(f.dir.Path(), ".git", "packed-refs"))
+	assert.NilError(t, err)
+	assert.Equal(t, f.version("describe", false), expected)
+	assert.Equal(t, f.version("describe", true), expected)
+}
+
+func TestGitVersionDirty(t *testing.T) {
+	f := newGitFixture(t)
+	defer f.dir.Remove()
+	f.commit("foo.txt", "foo")
+	f.commit("sub/dir/bar.txt", "bar")
+	f.git("tag", "v0.1.0")
+	assert.Equal(t, f.version("describe", true), "v0.1.0")
+
+	f.write("untracked.txt", "")
+	assert.Equal(t, f.version("describe", true), f.git("describe", "--tags", "--dirty"))
+	assert.Equal(t, f.version("describe", true), "v0.1.0")
+
+	f.write("foo.txt", "modified")
+	assert.Equal(t, f.version("describe", true), "v0.1.0-dirty")
+	assert.Equal(t, f.version("semver", true), "0.1.0+dirty")
+	assert.Equal(t, f.version("describe", false), "v0.1.0")
+
+	f.git("add", "foo.txt")
+	assert.Equal(t, f.version("describe", true), "v0.1.0-dirty")
+
+	f.git("reset", "-q", "--hard")
+	assert.Equal(t, f.version("describe", true), "v0.1.0")
+
+	assert.NilError(t, os.Remove(filepath.Join(f.dir.Path(), "foo.txt")))
+	assert.Equal(t, f.version("describe", true), "v0.1.0-dirty")
+}
+
+func TestGitVersionShallowClone(t *testing.T) {
+	f := newGitFixture(t)
+	defer f.dir.Remove()
+	f.commit("sub/dir/foo.txt", "foo")
+	f.git("tag", "v1.0.0")
+	f.commit("sub/dir/foo.txt", "bar")
+	f.git("tag", "-a", "-m", "Release", "v1.1.0")
+	f.commit("sub/dir/foo.txt", "qix")
+
+	for _, tc := range []struct {
+		depth    string
+		expected string
+	}{
+		{depth: "2", expected: "v1.1.0-1-g" + f.git("rev-parse", "--short=7", "HEAD")},
+		{depth: "1", expected: f.git("rev-parse", "--short=7", "HEAD")},
+	} {
+		clone := &gitFixture{t: t, dir: fs.NewDir(t, "clone")}
+		defer clone.dir.Remove()
+		clone.git("clone", "-q", "--depth", tc.depth, "--no-single-branch", "file://"+filepath.ToSlash(f.dir.Path()), ".")
+		// Tags beyond the shallow history are ignored.
+		clone.git("update-ref", "refs/tags/v1.0.0", f.git("rev-parse", "v1.0.0"))
+		assert.Equal(t, clone.git("describe", "--tags", "--always", "--abbrev=7"), tc.expected)
+		assert.Equal(t, clone.version("describe", false), tc.expected)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// globFiles returns the sorted files matching the patterns, where ** matches
+// any number of directories and matching directories are walked recursively.
+// Paths matching any of excludes, by base name or relative to the directory
+// preceding the first wildcard, are skipped.
+func globFiles(patterns, excludes []string) ([]string, error) {
+	seen := map[string]bool{}
+	var files []string
+	for _, pattern := range patterns {
+		pattern, err := expand(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pattern = path.Clean(filepath.ToSlash(pattern))
+		root := globRoot(pattern)
+		static := root == pattern
+		if static {
+			if _, err := os.Stat(root); err != nil {
+				return nil, fmt.Errorf("Source [%v] does not exist", root)
+			}
+		} else if err := checkGlob(pattern); err != nil {
+			return nil, err
+		}
+		err = filepath.Walk(filepath.FromSlash(root), func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && !static {
+					return nil
+				}
+				return err
+			}
+			rel, err := filepath.Rel(filepath.FromSlash(root), p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if rel != "." && excluded(excludes, rel, info.Name()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() || seen[p] {
+				return nil
+			}
+			if static || matchGlobOrParent(pattern, filepath.ToSlash(p), root) {
+				seen[p] = true
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// globRoot returns the directory preceding the first wildcard of pattern.
+func globRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			if i == 0 {
+				return "."
+			}
+			if i == 1 && segments[0] == "" {
+				return "/"
+			}
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return pattern
+}
+
+func checkGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("Invalid pattern [%v]", pattern)
+		}
+	}
+	return nil
+}
+
+// matchGlobOrParent reports whether name, or one of its parent directories
+// below root, matches pattern.
+func matchGlobOrParent(pattern, name, root string) bool {
+	for p := name; p != root && p != "." && p != "/"; p = path.Dir(p) {
+		if matchGlob(pattern, p) {
+			return true
+		}
+		if path.Dir(p) == p {
+			break
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether the slash separated name matches pattern, where
+// ** matches any number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
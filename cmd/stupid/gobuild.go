@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type goBuildOptions struct {
+	out     string
+	archive string
+	cgo     bool
+	jobs    int
+	// flags are passed to go build before the package.
+	flags []string
+}
+
+type goTarget struct {
+	os   string
+	arch string
+}
+
+func gobuild(args []string) error {
+	flags := newFlagSet("gobuild")
+	var targets stringsFlag
+	flags.Var(&targets, "targets", "")
+	var opts goBuildOptions
+	flags.StringVar(&opts.out, "out", "bin/{name}-{os}-{arch}{exe}", "")
+	flags.StringVar(&opts.archive, "archive", "", "")
+	flags.BoolVar(&opts.cgo, "cgo", false, "")
+	flags.IntVar(&opts.jobs, "jobs", 1, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No package to build")
+	}
+	parsed, err := parseTargets(targets)
+	if err != nil {
+		return err
+	}
+	switch opts.archive {
+	case "", "tar", "tar.gz", "tgz", "zip":
+	default:
+		return fmt.Errorf("Unknown archive [%v], expected one of [tar tar.gz tgz zip]", opts.archive)
+	}
+	if opts.jobs < 1 {
+		return fmt.Errorf("Invalid jobs [%v], expected at least 1", opts.jobs)
+	}
+	opts.flags = args[1:]
+	return goBuildTargets(args[0], parsed, opts)
+}
+
+// parseTargets parses comma separated OS/ARCH pairs, defaulting to the
+// platform stupid runs on.
+func parseTargets(values []string) ([]goTarget, error) {
+	var targets []goTarget
+	for _, value := range values {
+		for _, t := range strings.Split(value, ",") {
+			parts := strings.Split(strings.TrimSpace(t), "/")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("Invalid target [%v], expected OS/ARCH", t)
+			}
+			targets = append(targets, goTarget{os: parts[0], arch: parts[1]})
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, goTarget{os: runtime.GOOS, arch: runtime.GOARCH})
+	}
+	return targets, nil
+}
+
+// goBuildTargets builds pkg for each target, running up to opts.jobs builds
+// at the same time, and returns the first error in the order of targets.
+func goBuildTargets(pkg string, targets []goTarget, opts goBuildOptions) error {
+	name := packageName(pkg)
+	queue := make(chan int)
+	done := make(chan struct{})
+	errs := make([]error, len(targets))
+	for i := 0; i < opts.jobs; i++ {
+		go func() {
+			for j := range queue {
+				errs[j] = goBuildTarget(pkg, name, targets[j], opts)
+			}
+			done <- struct{}{}
+		}()
+	}
+	for i := range targets {
+		queue <- i
+	}
+	close(queue)
+	for i := 0; i < opts.jobs; i++ {
+		<-done
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func goBuildTarget(pkg, name string, target goTarget, opts goBuildOptions) error {
+	exe := ""
+	if target.os == "windows" {
+		exe = ".exe"
+	}
+	out, err := expand(targetPath(opts.out, name, target, exe))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Building [%v]\n", out)
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	args := append([]string{"build", "-o", out}, opts.flags...)
+	cmd := command("go", append(args, pkg)...)
+	cmd.Stdin = nil
+	cgo := "0"
+	if opts.cgo {
+		cgo = "1"
+	}
+	cmd.Env = append(os.Environ(), "GOOS="+target.os, "GOARCH="+target.arch, "CGO_ENABLED="+cgo)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Failed to build [%v]: %v", out, err)
+	}
+	switch opts.archive {
+	case "":
+		return nil
+	case "zip":
+		return zipFiles(strings.TrimSuffix(out, exe)+".zip", out)
+	}
+	return tarFiles(strings.TrimSuffix(out, exe)+"."+opts.archive, out)
+}
+
+// targetPath replaces the {name}, {os}, {arch} and {exe} placeholders of
+// template.
+func targetPath(template, name string, target goTarget, exe string) string {
+	return strings.NewReplacer(
+		"{name}", name,
+		"{os}", target.os,
+		"{arch}", target.arch,
+		"{exe}", exe,
+	).Replace(template)
+}
+
+// packageName returns the name go build gives to the binary of pkg, which
+// is either a directory, an import path or a go file.
+func packageName(pkg string) string {
+	if strings.HasSuffix(pkg, ".go") {
+		return strings.TrimSuffix(filepath.Base(pkg), ".go")
+	}
+	slash := filepath.ToSlash(pkg)
+	if filepath.IsAbs(pkg) || slash == "." || slash == ".." || strings.HasPrefix(slash, "./") || strings.HasPrefix(slash, "../") {
+		if abs, err := filepath.Abs(pkg); err == nil {
+			return filepath.Base(abs)
+		}
+	}
+	return path.Base(slash)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets([]string{"linux/amd64,darwin/arm64", "windows/386"})
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(targets), "[{linux amd64} {darwin arm64} {windows 386}]")
+
+	targets, err = parseTargets(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, fmt.Sprint(targets), fmt.Sprintf("[{%v %v}]", runtime.GOOS, runtime.GOARCH))
+
+	_, err = parseTargets([]string{"linux"})
+	assert.Error(t, err, "Invalid target [linux], expected OS/ARCH")
+}
+
+func TestTargetPath(t *testing.T) {
+	template := "bin/{name}-{os}-{arch}{exe}"
+	assert.Equal(t, targetPath(template, "tool", goTarget{"linux", "arm"}, ""), "bin/tool-linux-arm")
+	assert.Equal(t, targetPath(template, "tool", goTarget{"windows", "amd64"}, ".exe"), "bin/tool-windows-amd64.exe")
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, packageName("./cmd/tool"), "tool")
+	assert.Equal(t, packageName("github.com/foo/bar/cmd/tool"), "tool")
+	assert.Equal(t, packageName("main.go"), "main")
+	assert.Equal(t, packageName("."), "stupid")
+}
+
+func TestGoBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is required to build")
+	}
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("hello.go", "package main\n\nfunc main() {}\n"))
+	defer rootDirectory.Remove()
+
+	target := goTarget{runtime.GOOS, runtime.GOARCH}
+	err := goBuildTargets(rootDirectory.Join("hello.go"), []goTarget{target}, goBuildOptions{
+		out:     rootDirectory.Join("bin", "{name}-{os}{exe}"),
+		archive: "zip",
+		jobs:    2,
+	})
+	assert.NilError(t, err)
+
+	exe := ""
+	if runtime.GOOS == "windows" {
+		exe = ".exe"
+	}
+	out := filepath.Join(rootDirectory.Path(), "bin", "hello-"+runtime.GOOS)
+	assert.NilError(t, exec.Command(out+exe).Run())
+	r, err := zip.OpenReader(out + ".zip")
+	assert.NilError(t, err)
+	defer r.Close()
+	assert.Equal(t, len(r.File), 1)
+	assert.Equal(t, r.File[0].Name, "hello-"+runtime.GOOS+exe)
+
+	err = goBuildTargets(rootDirectory.Join("missing.go"), []goTarget{target}, goBuildOptions{out: out, jobs: 1})
+	assert.ErrorContains(t, err, "Failed to build ["+out+"]")
+}
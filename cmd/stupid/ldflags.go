@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func ldflags(args []string) error {
+	flags := newFlagSet("ldflags")
+	pkg := flags.String("pkg", "main", "")
+	var vars stringsFlag
+	flags.Var(&vars, "var", "")
+	dirtySuffix := &optionalString{fallback: "-dirty"}
+	flags.Var(dirtySuffix, "dirty-suffix", "")
+	shell := "sh"
+	if runtime.GOOS == "windows" {
+		shell = "cmd"
+	}
+	flags.StringVar(&shell, "shell", shell, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	value, err := ldflagsValue(*pkg, vars, dirtySuffix.value)
+	if err != nil {
+		return err
+	}
+	quoted, err := shellQuote(value, shell)
+	if err != nil {
+		return err
+	}
+	fmt.Print(quoted)
+	return nil
+}
+
+// ldflagsValue returns the -X flags setting each NAME=VALUE of vars, where
+// NAME is qualified with pkg unless it contains a dot, and VALUE is either
+// a literal, @git[:FORMAT] or @date[:LAYOUT].
+func ldflagsValue(pkg string, vars []string, dirtySuffix string) (string, error) {
+	var description *gitDescription
+	var flags []string
+	for _, v := range vars {
+		i := strings.IndexByte(v, '=')
+		if i <= 0 {
+			return "", fmt.Errorf("Invalid variable [%v], expected NAME=VALUE", v)
+		}
+		name, value := v[:i], v[i+1:]
+		if !strings.Contains(name, ".") {
+			name = pkg + "." + name
+		}
+		source, arg := value, ""
+		if j := strings.IndexByte(value, ':'); j >= 0 {
+			source, arg = value[:j], value[j+1:]
+		}
+		switch {
+		case strings.HasPrefix(value, "@@"):
+			value = value[1:]
+		case source == "@git":
+			if description == nil {
+				d, err := describeGit(".", dirtySuffix != "")
+				if err != nil {
+					return "", err
+				}
+				description = &d
+			}
+			var err error
+			if arg == "commit" {
+				value = description.commit
+			} else if value, err = description.format(orDefault(arg, "describe"), dirtySuffix); err != nil {
+				return "", err
+			}
+		case source == "@date":
+			var err error
+			if value, err = formatDate(dateOptions{format: orDefault(arg, time.RFC3339)}); err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(value, "@"):
+			return "", fmt.Errorf("Unknown source [%v], expected @git or @date", source)
+		}
+		flag, err := goQuote(name + "=" + value)
+		if err != nil {
+			return "", err
+		}
+		flags = append(flags, "-X "+flag)
+	}
+	return strings.Join(flags, " "), nil
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// goQuote quotes s, if needed, the way the go command splits -ldflags.
+func goQuote(s string) (string, error) {
+	if !strings.ContainsAny(s, " \t\n\r'\"") {
+		return s, nil
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'", nil
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`, nil
+	}
+	return "", fmt.Errorf("Cannot quote [%v] containing both single and double quotes", s)
+}
+
+// shellQuote quotes s as a single word for sh, cmd or none.
+func shellQuote(s, shell string) (string, error) {
+	switch shell {
+	case "none":
+		return s, nil
+	case "sh":
+		return "'" + strings.Replace(s, "'", `'\''`, -1) + "'", nil
+	case "cmd":
+		var b strings.Builder
+		b.WriteByte('"')
+		backslashes := 0
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				backslashes++
+			case '"':
+				b.WriteString(strings.Repeat(`\`, backslashes+1))
+				backslashes = 0
+			default:
+				backslashes = 0
+			}
+			b.WriteByte(s[i])
+		}
+		b.WriteString(strings.Repeat(`\`, backslashes))
+		b.WriteByte('"')
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("Unknown shell [%v], expected one of [sh cmd none]", shell)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLdflagsValue(t *testing.T) {
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	os.Setenv("SOURCE_DATE_EPOCH", "1520435045")
+
+	value, err := ldflagsValue("main", []string{
+		"date=@date",
+		"day=@date:%Y%m%d",
+		"github.com/foo/bar/version.Name=stupid",
+		"message=hello world",
+		"quote=it's",
+		"at=@@home",
+	}, "")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "-X main.date=2018-03-07T15:04:05Z -X main.day=20180307 -X github.com/foo/bar/version.Name=stupid "+
+		"-X 'main.message=hello world' -X \"main.quote=it's\" -X main.at=@home")
+
+	_, err = ldflagsValue("main", []string{"version"}, "")
+	assert.Error(t, err, "Invalid variable [version], expected NAME=VALUE")
+	_, err = ldflagsValue("main", []string{"version=@svn"}, "")
+	assert.Error(t, err, "Unknown source [@svn], expected @git or @date")
+	_, err = ldflagsValue("main", []string{`quotes='"`}, "")
+	assert.Error(t, err, `Cannot quote [main.quotes='"] containing both single and double quotes`)
+}
+
+func TestLdflagsValueWithGit(t *testing.T) {
+	f := newGitFixture(t)
+	defer f.dir.Remove()
+	f.commit("foo.txt", "foo")
+	f.git("tag", "v1.0.0")
+	f.write("foo.txt", "modified")
+
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	defer os.Chdir(cwd)
+	assert.NilError(t, os.Chdir(f.dir.Path()))
+
+	value, err := ldflagsValue("main", []string{"version=@git", "semver=@git:semver", "commit=@git:commit"}, "-dirty")
+	assert.NilError(t, err)
+	assert.Equal(t, value, "-X main.version=v1.0.0-dirty -X main.semver=1.0.0+dirty -X main.commit="+f.git("rev-parse", "HEAD"))
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, tc := range []struct {
+		shell    string
+		value    string
+		expected string
+	}{
+		{shell: "none", value: "-X 'main.a=b c'", expected: "-X 'main.a=b c'"},
+		{shell: "sh", value: "-X main.a=b", expected: "'-X main.a=b'"},
+		{shell: "sh", value: "-X 'main.a=b c'", expected: `'-X '\''main.a=b c'\'''`},
+		{shell: "cmd", value: "-X 'main.a=b c'", expected: `"-X 'main.a=b c'"`},
+		{shell: "cmd", value: `-X "main.a=it's" -X main.dir=C:\`, expected: `"-X \"main.a=it's\" -X main.dir=C:\\"`},
+	} {
+		quoted, err := shellQuote(tc.value, tc.shell)
+		assert.NilError(t, err)
+		assert.Equal(t, quoted, tc.expected)
+	}
+	_, err := shellQuote("", "fish")
+	assert.Error(t, err, "Unknown shell [fish], expected one of [sh cmd none]")
+}
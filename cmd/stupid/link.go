@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type linkOptions struct {
+	symbolic     bool
+	force        bool
+	relative     bool
+	fallbackCopy bool
+}
+
+func ln(args []string) error {
+	var opts linkOptions
+	flags := newFlagSet("ln")
+	flags.BoolVar(&opts.symbolic, "s", false, "")
+	flags.BoolVar(&opts.symbolic, "symbolic", false, "")
+	flags.BoolVar(&opts.force, "force", false, "")
+	flags.BoolVar(&opts.relative, "relative", false, "")
+	flags.BoolVar(&opts.fallbackCopy, "fallback-copy", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("Expected exactly a target and a link")
+	}
+	return linkFile(args[0], args[1], opts)
+}
+
+// linkFile creates a link to target. If link is an existing directory, not a
+// symbolic link to one, or ends with a slash, the link is created inside it.
+func linkFile(target, link string, opts linkOptions) error {
+	target, err := expand(target)
+	if err != nil {
+		return err
+	}
+	link, err = expand(link)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		if strings.HasSuffix(link, "/") {
+			link = filepath.Join(link, filepath.Base(target))
+			info, err = os.Lstat(link)
+		}
+	} else if err == nil && info.IsDir() {
+		link = filepath.Join(link, filepath.Base(target))
+		info, err = os.Lstat(link)
+	}
+	// source is the target as seen from the current directory.
+	source := target
+	if opts.symbolic && !filepath.IsAbs(target) && !opts.relative {
+		source = filepath.Join(filepath.Dir(link), target)
+	}
+	if err == nil {
+		if !opts.force {
+			return fmt.Errorf("Link [%v] already exists", link)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("Cannot replace directory [%v] with a link", link)
+		}
+		if sourceInfo, err := os.Stat(source); err == nil && os.SameFile(sourceInfo, info) {
+			return fmt.Errorf("Link [%v] and target [%v] are the same file", link, source)
+		}
+		if err = os.Remove(link); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return err
+	}
+	if opts.relative {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return err
+		}
+		dir, err := filepath.Abs(filepath.Dir(link))
+		if err != nil {
+			return err
+		}
+		if target, err = filepath.Rel(dir, abs); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Linking [%v] to [%v]\n", link, target)
+	if opts.symbolic {
+		err = os.Symlink(target, link)
+	} else {
+		err = os.Link(source, link)
+	}
+	if err == nil || !opts.fallbackCopy || !linkUnsupported(err) {
+		return err
+	}
+	fmt.Printf("Linking failed (%v), copying [%v] to [%v]\n", err, source, link)
+	info, err = os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return copyDirectory(source, link, info.Mode())
+	}
+	return copyFile(source, link, info.Mode())
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestSymbolicLinkToDir(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("build",
+			fs.WithDir("v1",
+				fs.WithFile("foo.txt", "foo")),
+			fs.WithDir("v2",
+				fs.WithFile("foo.txt", "bar"))))
+	defer rootDirectory.Remove()
+
+	latest := filepath.Join(rootDirectory.Path(), "build", "latest")
+	err := linkFile("v1", latest, linkOptions{symbolic: true})
+	assert.NilError(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(latest, "foo.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "foo")
+
+	err = linkFile(filepath.Join(rootDirectory.Path(), "build", "v2"), latest, linkOptions{symbolic: true})
+	assert.Error(t, err, "Link ["+latest+"] already exists")
+
+	err = linkFile(filepath.Join(rootDirectory.Path(), "build", "v2"), latest, linkOptions{symbolic: true, force: true, relative: true})
+	assert.NilError(t, err)
+	target, err := os.Readlink(latest)
+	assert.NilError(t, err)
+	assert.Equal(t, target, "v2")
+}
+
+func TestHardLinkIntoDir(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo"),
+		fs.WithDir("destination"))
+	defer rootDirectory.Remove()
+
+	err := linkFile(filepath.Join(rootDirectory.Path(), "foo.txt"), filepath.Join(rootDirectory.Path(), "destination"), linkOptions{})
+	assert.NilError(t, err)
+	err = linkFile(filepath.Join(rootDirectory.Path(), "foo.txt"), filepath.Join(rootDirectory.Path(), "other")+"/", linkOptions{})
+	assert.NilError(t, err)
+
+	source, err := os.Stat(filepath.Join(rootDirectory.Path(), "foo.txt"))
+	assert.NilError(t, err)
+	for _, dir := range []string{"destination", "other"} {
+		info, err := os.Stat(filepath.Join(rootDirectory.Path(), dir, "foo.txt"))
+		assert.NilError(t, err)
+		assert.Assert(t, os.SameFile(source, info), dir)
+	}
+}
+
+func TestLinkToItself(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo"),
+		fs.WithDir("destination"))
+	defer rootDirectory.Remove()
+
+	foo := filepath.Join(rootDirectory.Path(), "foo.txt")
+	for _, opts := range []linkOptions{{force: true}, {force: true, fallbackCopy: true}, {symbolic: true, force: true}} {
+		err := linkFile(foo, foo, opts)
+		assert.Error(t, err, "Link ["+foo+"] and target ["+foo+"] are the same file")
+	}
+	err := linkFile(foo, filepath.Join(rootDirectory.Path(), "destination"), linkOptions{})
+	assert.NilError(t, err)
+	err = linkFile(foo, filepath.Join(rootDirectory.Path(), "destination"), linkOptions{force: true})
+	assert.ErrorContains(t, err, "are the same file")
+	content, err := ioutil.ReadFile(foo)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "foo")
+
+	err = linkFile(filepath.Join(rootDirectory.Path(), "missing.txt"), filepath.Join(rootDirectory.Path(), "copy.txt"), linkOptions{fallbackCopy: true})
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(rootDirectory.Path(), "copy.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// linkUnsupported tells whether err means that the file system or the
+// platform does not support the link.
+func linkUnsupported(err error) bool {
+	if linkErr, ok := err.(*os.LinkError); ok {
+		err = linkErr.Err
+	}
+	errno, ok := err.(syscall.Errno)
+	return ok && (errno == syscall.EPERM || errno == syscall.EXDEV || errno == syscall.ENOTSUP || errno == syscall.EOPNOTSUPP)
+}
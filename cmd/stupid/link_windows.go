@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	errorInvalidFunction  syscall.Errno = 1
+	errorNotSameDevice    syscall.Errno = 17
+	errorNotSupported     syscall.Errno = 50
+	errorPrivilegeNotHeld syscall.Errno = 1314
+)
+
+// linkUnsupported tells whether err means that the file system or the
+// platform does not support the link, e.g. symbolic links without the
+// required privilege.
+func linkUnsupported(err error) bool {
+	if linkErr, ok := err.(*os.LinkError); ok {
+		err = linkErr.Err
+	}
+	switch err {
+	case errorInvalidFunction, errorNotSameDevice, errorNotSupported, errorPrivilegeNotHeld:
+		return true
+	}
+	return false
+}
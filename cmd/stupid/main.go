@@ -1,61 +1,160 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
-	"time"
-
-	"github.com/mitchellh/go-homedir"
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	args, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+	if len(args) < 1 {
 		printUsage()
 		return
 	}
-	args := os.Args[1:]
 	action := args[0]
-	var err error
 	switch action {
 	case "help":
 	case "--help":
 		printUsage()
+	case "--version":
+		err = printVersion(args[1:])
+	case "append":
+		checkArguments(args, 2)
+		err = write(args[1:], os.O_APPEND)
+	case "cat":
+		checkArguments(args, 2)
+		err = cat(args[1:])
+	case "chmod":
+		checkArguments(args, 3)
+		err = chmod(args[1:])
 	case "cp":
 		checkArguments(args, 3)
 		err = copy(args[1:len(args)-1], args[len(args)-1])
 	case "date":
-		fmt.Print(time.Now().Format(time.RFC3339))
+		err = date(args[1:])
+	case "dir":
+		err = dir(args[1:])
+	case "find":
+		err = find(args[1:])
+	case "get":
+		err = get(args[1:])
+	case "gitversion":
+		err = gitVersion(args[1:])
+	case "gobuild":
+		err = gobuild(args[1:])
 	case "home":
-		home, err := homedir.Dir()
-		if err == nil {
-			fmt.Print(home)
-		}
+		err = dir([]string{"home"})
+	case "ldflags":
+		err = ldflags(args[1:])
+	case "ln":
+		checkArguments(args, 3)
+		err = ln(args[1:])
 	case "mkdir":
 		checkArguments(args, 2)
 		err = mkDir(args[1:])
+	case "mktemp":
+		err = mktemp(args[1:])
+	case "newer":
+		err = newer(args[1:])
+	case "parallel":
+		err = parallel(args[1:])
+	case "path":
+		checkArguments(args, 3)
+		err = pathUtil(args[1:])
+	case "render":
+		checkArguments(args, 3)
+		err = render(args[1:])
+	case "replace":
+		checkArguments(args, 2)
+		err = replace(args[1:])
+	case "retry":
+		err = retry(args[1:])
 	case "rm":
 		checkArguments(args, 2)
 		err = remove(args[1:])
+	case "run":
+		checkArguments(args, 3)
+		err = run(args[1:])
 	case "silence":
 		err = silence()
+	case "sum":
+		checkArguments(args, 2)
+		err = sum(args[1:])
 	case "tar":
 		checkArguments(args, 3)
 		err = tarFiles(args[len(args)-1], args[1:len(args)-1]...)
+	case "test":
+		checkArguments(args, 2)
+		err = test(args[1:])
+	case "timeout":
+		err = timeout(args[1:])
+	case "touch":
+		checkArguments(args, 2)
+		err = touch(args[1:])
 	case "untar":
 		checkArguments(args, 3)
 		err = untar(args[1], args[2])
+	case "version":
+		err = printVersion(args[1:])
+	case "watch":
+		err = watch(args[1:])
+	case "which":
+		checkArguments(args, 2)
+		err = which(args[1:])
+	case "write":
+		checkArguments(args, 2)
+		err = write(args[1:], os.O_TRUNC)
 	default:
 		fmt.Fprintln(os.Stderr, "I don't know what", action, "means")
 		printUsage()
 		os.Exit(-3)
 	}
+	if code, ok := err.(exitError); ok {
+		os.Exit(int(code))
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(-1)
 	}
 }
 
+// exitError makes stupid exit with the given code without printing anything.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// parseOptions consumes the global options preceding the command.
+func parseOptions(args []string) ([]string, error) {
+	mode, err := parseExpansion(os.Getenv("STUPID_EXPAND"))
+	if err != nil {
+		return nil, err
+	}
+	for ; len(args) > 0; args = args[1:] {
+		switch args[0] {
+		case "--expand-env":
+			mode = expandEnv
+		case "--strict":
+			mode = expandStrict
+		case "--no-expand":
+			mode = expandNone
+		default:
+			expandMode = mode
+			return args, nil
+		}
+	}
+	expandMode = mode
+	return args, nil
+}
+
 func checkArguments(args []string, max int) {
 	if len(args) < max {
 		fmt.Fprintln(os.Stderr, "Not enough arguments, I'm the stupid one, you fix it")
@@ -64,15 +163,126 @@ func checkArguments(args []string, max int) {
 	}
 }
 
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	return flags
+}
+
+// stringsFlag is a flag which can be repeated, accumulating its values.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// listFlag is a repeatable flag which also collects the positional
+// arguments following it, so that --out A B --in C D works.
+type listFlag struct {
+	values  *stringsFlag
+	current **stringsFlag
+}
+
+func (l listFlag) String() string {
+	if l.values == nil {
+		return ""
+	}
+	return l.values.String()
+}
+
+func (l listFlag) Set(value string) error {
+	*l.current = l.values
+	return l.values.Set(value)
+}
+
+// parseFlags parses args allowing flags to be interspersed with positional
+// arguments, until a "--" terminator after which everything is positional.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positionals []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positionals, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positionals, rest...), nil
+		}
+		positionals = append(positionals, rest[0])
+		args = rest[1:]
+	}
+}
+
+// parseListFlags is like parseFlags, except that the positional arguments
+// preceding "--" are added to the listFlag given last, returning the
+// arguments following "--".
+func parseListFlags(flags *flag.FlagSet, args []string, current **stringsFlag) ([]string, error) {
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return nil, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return rest, nil
+		}
+		if *current == nil {
+			return nil, fmt.Errorf("Unexpected arguments %v", rest)
+		}
+		(*current).Set(rest[0])
+		args = rest[1:]
+	}
+}
+
 func printUsage() {
-	fmt.Println("I'm stupidly manipulating files and directories")
+	v, _, _ := buildInfo()
+	fmt.Println("I'm stupidly manipulating files and directories, version", v)
+	fmt.Println("stupid [--expand-env|--strict|--no-expand] COMMAND")
+	fmt.Println("* stupid append [--newline lf|crlf|native] [--escape] [--no-newline] FILE TEXT...")
+	fmt.Println("* stupid cat [--out FILE] SRCS")
+	fmt.Println("* stupid chmod [-R] MODE SRCS")
 	fmt.Println("* stupid cp SRCS DST")
-	fmt.Println("* stupid date")
+	fmt.Println("* stupid date [--utc] [--unix] [--format LAYOUT] [--add DURATION] [--file FILE]")
+	fmt.Println("* stupid dir [--slash] [--ensure] config|cache|data|temp|home|cwd")
+	fmt.Println("* stupid find [--name GLOB] [--type f|d|l] [--newer FILE] [--size [+-]N[ckMG]] [--maxdepth N] [--exclude GLOB] [--print0] ROOTS")
+	fmt.Println("* stupid get [--default VALUE] [--raw] FILE PATH")
+	fmt.Println("* stupid gitversion [--format semver|describe|short] [--dirty-suffix[=SUFFIX]] [PATH]")
+	fmt.Println("* stupid gobuild [--targets OS/ARCH,...] [--out TEMPLATE] [--jobs N] [--cgo] [--archive tar|tar.gz|tgz|zip] PKG [-- BUILDFLAGS]")
 	fmt.Println("* stupid home")
+	fmt.Println("* stupid ldflags [--pkg PKG] [--var NAME=VALUE] [--dirty-suffix[=SUFFIX]] [--shell sh|cmd|none]")
+	fmt.Println("* stupid ln [-s] [--force] [--relative] [--fallback-copy] TARGET LINK")
+	fmt.Println("* stupid mktemp [-d] [--prefix PREFIX] [--in DIR]")
+	fmt.Println("* stupid newer --out TARGETS --in SRCS [--exclude GLOB] [--hash-cache FILE] [--print]")
+	fmt.Println("* stupid parallel [--jobs N] [--fail-fast] [--worst] -- CMD1 -- CMD2...")
+	fmt.Println("* stupid path [--native|--slash] abs|dir|base|ext|stem|join|clean|to-slash|from-slash PATHS")
+	fmt.Println("* stupid path [--native|--slash] rel BASE PATHS")
+	fmt.Println("* stupid render [--data FILE] [--set KEY=VALUE] TEMPLATE DST")
+	fmt.Println("* stupid replace --regex PATTERN --with REPL [--literal] [--count N] [--expect N] SRCS")
+	fmt.Println("* stupid retry [--attempts N] [--delay DURATION] [--backoff constant|linear|exp] -- CMD ARGS")
 	fmt.Println("* stupid rm SRCS")
+	fmt.Println("* stupid run --tmpdir VAR -- CMD ARGS")
 	fmt.Println("* stupid silence")
+	fmt.Println("* stupid sum [--algo sha256|sha512|sha1|md5] SRCS")
+	fmt.Println("* stupid sum [--algo sha256|sha512|sha1|md5] --check FILE")
 	fmt.Println("* stupid tar SRCS DST")
+	fmt.Println("* stupid test [--print] -e|-f|-d|-L|-s|-x|--empty|--any PATHS")
+	fmt.Println("* stupid test [--print] --newer A B")
+	fmt.Println("* stupid timeout [--kill-after DURATION] DURATION -- CMD ARGS")
+	fmt.Println("* stupid touch [--date DATE] [--reference FILE] [--no-create] FILES")
 	fmt.Println("* stupid untar SRC DST")
+	fmt.Println("* stupid version [--require CONSTRAINTS]")
+	fmt.Println("* stupid watch --path SRCS [--exclude GLOB] [--debounce DURATION] [--interval DURATION] [--queue] -- CMD ARGS")
+	fmt.Println("* stupid which [--all] NAMES")
+	fmt.Println("* stupid write [--newline lf|crlf|native] [--escape] [--no-newline] FILE TEXT...")
 }
 
 func glob(sources []string, fail bool) ([]string, error) {
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+func newer(args []string) error {
+	flags := newFlagSet("newer")
+	var outs, ins, excludes stringsFlag
+	var current *stringsFlag
+	flags.Var(listFlag{&outs, &current}, "out", "")
+	flags.Var(listFlag{&ins, &current}, "in", "")
+	flags.Var(&excludes, "exclude", "")
+	hashCache := flags.String("hash-cache", "", "")
+	printResult := flags.Bool("print", false, "")
+	args, err := parseListFlags(flags, args, &current)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	stale, err := isStale(outs, ins, excludes, *hashCache)
+	if err != nil {
+		return err
+	}
+	if *printResult {
+		fmt.Print(stale)
+		return nil
+	}
+	if !stale {
+		return exitError(1)
+	}
+	return nil
+}
+
+// isStale reports whether any of the targets is missing or older than any
+// of the files matching the sources. With a hash cache, sources newer than
+// the oldest target are only considered changed if their content differs
+// from the one recorded when they were older than all the targets.
+func isStale(targets, sources, excludes []string, hashCache string) (bool, error) {
+	if len(targets) == 0 {
+		return false, fmt.Errorf("Expected at least one --out TARGET")
+	}
+	if len(sources) == 0 {
+		return false, fmt.Errorf("Expected at least one --in SRCS")
+	}
+	files, err := globFiles(sources, excludes)
+	if err != nil {
+		return false, err
+	}
+	missing := false
+	var oldest time.Time
+	for _, target := range targets {
+		target, err := expand(target)
+		if err != nil {
+			return false, err
+		}
+		info, err := os.Stat(target)
+		if os.IsNotExist(err) {
+			missing = true
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+	if hashCache == "" {
+		if missing {
+			return true, nil
+		}
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				return false, err
+			}
+			if info.ModTime().After(oldest) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	hashCache, err = expand(hashCache)
+	if err != nil {
+		return false, err
+	}
+	cache, err := readHashCache(hashCache)
+	if err != nil {
+		return false, err
+	}
+	stale := missing
+	updated := map[string]string{}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		sum, err := hashFile("sha256", file)
+		if err != nil {
+			return false, err
+		}
+		key := filepath.ToSlash(file)
+		if !missing && !info.ModTime().After(oldest) {
+			updated[key] = sum
+			continue
+		}
+		if cached, ok := cache[key]; ok && cached == sum {
+			updated[key] = sum
+			continue
+		}
+		stale = true
+		if cached, ok := cache[key]; ok {
+			updated[key] = cached
+		}
+	}
+	return stale, writeHashCache(hashCache, updated)
+}
+
+// readHashCache reads the sha256 checksums of the cache, in the format of
+// stupid sum, returning an empty cache if the file does not exist.
+func readHashCache(file string) (map[string]string, error) {
+	cache := map[string]string{}
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimRight(scanner.Text(), "\r"), "  ", 2)
+		if len(fields) == 2 {
+			cache[fields[1]] = fields[0]
+		}
+	}
+	return cache, scanner.Err()
+}
+
+func writeHashCache(file string, cache map[string]string) error {
+	paths := make([]string, 0, len(cache))
+	for p := range cache {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&b, "%v  %v\n", cache[p], p)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if content, err := ioutil.ReadFile(file); err == nil && string(content) == b.String() {
+		return nil
+	}
+	return ioutil.WriteFile(file, []byte(b.String()), 0644)
+}
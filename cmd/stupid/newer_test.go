@@ -0,0 +1,146 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "src/*.go", name: "src/main.go", expected: true},
+		{pattern: "src/*.go", name: "src/sub/main.go", expected: false},
+		{pattern: "src/**/*.go", name: "src/main.go", expected: true},
+		{pattern: "src/**/*.go", name: "src/a/b/main.go", expected: true},
+		{pattern: "src/**/*.go", name: "src/a/b/main.c", expected: false},
+		{pattern: "**", name: "a/b", expected: true},
+		{pattern: "src/**", name: "src", expected: true},
+		{pattern: "src/**/test", name: "src/a/test", expected: true},
+		{pattern: "src/**/test", name: "src/a/test/b", expected: false},
+	} {
+		assert.Equal(t, matchGlob(tc.pattern, tc.name), tc.expected, "%v %v", tc.pattern, tc.name)
+	}
+}
+
+func TestGlobFiles(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("go.mod", ""),
+		fs.WithDir("src",
+			fs.WithFile("main.go", ""),
+			fs.WithFile("main_test.go", ""),
+			fs.WithDir("sub",
+				fs.WithFile("sub.go", ""),
+				fs.WithFile("data.txt", "")),
+			fs.WithDir("vendor",
+				fs.WithFile("dep.go", ""))))
+	defer rootDirectory.Remove()
+	root := filepath.ToSlash(rootDirectory.Path())
+
+	files, err := globFiles([]string{root + "/src/**/*.go", root + "/go.mod"}, []string{"*_test.go", "vendor"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{
+		rootDirectory.Join("go.mod"),
+		rootDirectory.Join("src", "main.go"),
+		rootDirectory.Join("src", "sub", "sub.go"),
+	})
+
+	files, err = globFiles([]string{root + "/src/s*", root + "/src/sub"}, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{rootDirectory.Join("src", "sub", "data.txt"), rootDirectory.Join("src", "sub", "sub.go")})
+
+	files, err = globFiles([]string{root + "/missing/*"}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 0)
+
+	_, err = globFiles([]string{root + "/missing"}, nil)
+	assert.Error(t, err, "Source ["+root+"/missing] does not exist")
+}
+
+func TestNewer(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("src",
+			fs.WithFile("main.go", "main"),
+			fs.WithFile("main_test.go", "test")),
+		fs.WithDir("bin",
+			fs.WithFile("a", ""),
+			fs.WithFile("b", "")))
+	defer rootDirectory.Remove()
+	past := time.Now().Add(-time.Hour)
+	setTime := func(t *testing.T, path string, date time.Time) {
+		assert.NilError(t, os.Chtimes(rootDirectory.Join(path), date, date))
+	}
+	setTime(t, "src/main.go", past)
+	setTime(t, "src/main_test.go", past)
+
+	run := func(args ...string) error {
+		return newer(append([]string{"--out", rootDirectory.Join("bin", "a"), rootDirectory.Join("bin", "b"), "--in"}, args...))
+	}
+	src := rootDirectory.Join("src")
+	assert.Equal(t, run(src), exitError(1))
+
+	setTime(t, "bin/b", past.Add(-time.Minute))
+	assert.NilError(t, run(src))
+	assert.Equal(t, run(src, "--exclude", "*.go"), exitError(1))
+
+	setTime(t, "src/main_test.go", past.Add(-2*time.Minute))
+	assert.Equal(t, run(src, "--exclude", "main.go"), exitError(1))
+	assert.NilError(t, newer([]string{"--out", rootDirectory.Join("bin", "c"), "--in", src}))
+	assert.Error(t, newer([]string{"--out", rootDirectory.Join("bin", "c"), "src"}), "Expected at least one --in SRCS")
+}
+
+func TestNewerWithHashCache(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("main.go", "main"),
+		fs.WithFile("tool", ""))
+	defer rootDirectory.Remove()
+	past := time.Now().Add(-time.Hour)
+	assert.NilError(t, os.Chtimes(rootDirectory.Join("main.go"), past, past))
+	target, source, cache := rootDirectory.Join("tool"), rootDirectory.Join("main.go"), rootDirectory.Join("cache", "sums")
+
+	stale, err := isStale([]string{target}, []string{source}, nil, cache)
+	assert.NilError(t, err)
+	assert.Assert(t, !stale)
+	sums, err := readHashCache(cache)
+	assert.NilError(t, err)
+	original, err := hashFile("sha256", source)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sums, map[string]string{filepath.ToSlash(source): original})
+
+	// Touched without changing the content, like after a git checkout.
+	now := time.Now().Add(time.Hour)
+	assert.NilError(t, os.Chtimes(source, now, now))
+	stale, err = isStale([]string{target}, []string{source}, nil, cache)
+	assert.NilError(t, err)
+	assert.Assert(t, !stale)
+	stale, err = isStale([]string{target}, []string{source}, nil, "")
+	assert.NilError(t, err)
+	assert.Assert(t, stale)
+
+	// Modified, until the target is rebuilt.
+	assert.NilError(t, ioutil.WriteFile(source, []byte("modified"), 0644))
+	assert.NilError(t, os.Chtimes(source, now, now))
+	for i := 0; i < 2; i++ {
+		stale, err = isStale([]string{target}, []string{source}, nil, cache)
+		assert.NilError(t, err)
+		assert.Assert(t, stale)
+	}
+	later := now.Add(time.Minute)
+	assert.NilError(t, os.Chtimes(target, later, later))
+	stale, err = isStale([]string{target}, []string{source}, nil, cache)
+	assert.NilError(t, err)
+	assert.Assert(t, !stale)
+	sums, err = readHashCache(cache)
+	assert.NilError(t, err)
+	expected, err := hashFile("sha256", source)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, sums, map[string]string{filepath.ToSlash(source): expected})
+}
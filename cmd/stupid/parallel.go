@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type parallelOptions struct {
+	jobs     int
+	failFast bool
+	worst    bool
+}
+
+func parallel(args []string) error {
+	var opts parallelOptions
+	flags := newFlagSet("parallel")
+	flags.IntVar(&opts.jobs, "jobs", 0, "")
+	flags.BoolVar(&opts.failFast, "fail-fast", false, "")
+	flags.BoolVar(&opts.worst, "worst", false, "")
+	// No flag collects the positional arguments, which are rejected
+	// before the first "--".
+	var current *stringsFlag
+	args, err := parseListFlags(flags, args, &current)
+	if err != nil {
+		return err
+	}
+	commands, err := parseCommands(args)
+	if err != nil {
+		return err
+	}
+	if opts.jobs <= 0 {
+		opts.jobs = len(commands)
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	return runParallel(commands, opts, signals, os.Stdout, os.Stderr)
+}
+
+// parseCommands splits args on "--", each command being either a single
+// argument split like a shell would, or several arguments.
+func parseCommands(args []string) ([][]string, error) {
+	var commands [][]string
+	var current []string
+	for i := 0; i <= len(args); i++ {
+		if i < len(args) && args[i] != "--" {
+			current = append(current, args[i])
+			continue
+		}
+		if len(current) == 1 {
+			words, err := splitCommand(current[0])
+			if err != nil {
+				return nil, err
+			}
+			current = words
+		}
+		if len(current) > 0 {
+			commands = append(commands, current)
+		}
+		current = nil
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("No command to run")
+	}
+	return commands, nil
+}
+
+// splitCommand splits s into words separated by blanks, honoring single
+// and double quotes. Backslashes only escape quotes, blanks and themselves,
+// so that Windows paths can be used as is.
+func splitCommand(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote == '\'' {
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+			continue
+		}
+		escapable := "\\\"' \t"
+		if quote == '"' {
+			escapable = "\\\""
+		}
+		if c == '\\' && i+1 < len(s) && strings.IndexByte(escapable, s[i+1]) >= 0 {
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+			continue
+		}
+		switch {
+		case c == quote:
+			quote = 0
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+			inWord = true
+		case quote == 0 && strings.IndexByte(" \t\r\n", c) >= 0:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("Unterminated quote in [%v]", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// commandLabels names the commands after their programs, numbering the
+// duplicates, and pads the names to the same width.
+func commandLabels(commands [][]string) []string {
+	labels := make([]string, len(commands))
+	counts := map[string]int{}
+	width := 0
+	for i, command := range commands {
+		name := filepath.Base(command[0])
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		counts[name]++
+		if counts[name] > 1 {
+			name = fmt.Sprintf("%v#%v", name, counts[name])
+		}
+		labels[i] = name
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for i, label := range labels {
+		labels[i] = fmt.Sprintf("%-*s | ", width, label)
+	}
+	return labels
+}
+
+type parallelResult struct {
+	index int
+	err   error
+}
+
+// runParallel runs the commands, up to opts.jobs at the same time, prefixing
+// each line of their outputs with their labels. Signals are forwarded to
+// all the running commands. It returns the exit code of the first command
+// which failed, or the highest one if opts.worst is set, the commands
+// stopped by opts.failFast not counting as failed.
+func runParallel(commands [][]string, opts parallelOptions, signals <-chan os.Signal, stdout, stderr io.Writer) error {
+	labels := commandLabels(commands)
+	var mutex sync.Mutex
+	results := make(chan parallelResult)
+	running := map[int]*process{}
+	stopped := map[int]bool{}
+	next, failed := 0, 0
+	stopping := false
+	var interrupted syscall.Signal
+	var timers []*time.Timer
+	defer func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}()
+	// stop signals the running commands, killing them if they are still
+	// running after a grace period.
+	stop := func(sig syscall.Signal) {
+		stopping = true
+		for i, p := range running {
+			stopped[i] = true
+			cmd := p.cmd
+			signalProcessGroup(cmd, sig)
+			timers = append(timers, time.AfterFunc(5*time.Second, func() {
+				signalProcessGroup(cmd, syscall.SIGKILL)
+			}))
+		}
+	}
+	finish := func(i int, err error) {
+		if err == nil {
+			return
+		}
+		code := 1
+		if exitErr, ok := exitCode(err).(exitError); ok {
+			code = int(exitErr)
+		}
+		mutex.Lock()
+		fmt.Fprintf(stderr, "%v[%v] failed: %v\n", labels[i], strings.Join(commands[i], " "), err)
+		mutex.Unlock()
+		if failed == 0 || opts.worst && code > failed {
+			failed = code
+		}
+		if opts.failFast && !stopping {
+			stop(syscall.SIGTERM)
+		}
+	}
+	for {
+		for !stopping && next < len(commands) && len(running) < opts.jobs {
+			i := next
+			next++
+			out := &prefixWriter{w: stdout, prefix: labels[i], mutex: &mutex}
+			errOut := &prefixWriter{w: stderr, prefix: labels[i], mutex: &mutex}
+			cmd := command(commands[i][0], commands[i][1:]...)
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = nil, out, errOut
+			p, err := startProcess(cmd)
+			if err != nil {
+				finish(i, err)
+				continue
+			}
+			running[i] = p
+			go func() {
+				err := <-p.done
+				out.flush()
+				errOut.flush()
+				results <- parallelResult{i, err}
+			}()
+		}
+		if len(running) == 0 {
+			break
+		}
+		select {
+		case result := <-results:
+			delete(running, result.index)
+			if interrupted == 0 && !stopped[result.index] {
+				finish(result.index, result.err)
+			}
+		case sig := <-signals:
+			interrupted = syscall.SIGTERM
+			if s, ok := sig.(syscall.Signal); ok {
+				interrupted = s
+			}
+			stop(interrupted)
+		}
+	}
+	if interrupted != 0 {
+		return exitError(128 + int(interrupted))
+	}
+	if failed != 0 {
+		return exitError(failed)
+	}
+	return nil
+}
+
+// prefixWriter writes each line with a prefix, the lines of several writers
+// sharing the same mutex not being mixed.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mutex  *sync.Mutex
+	buffer []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buffer = append(p.buffer, b...)
+	for {
+		i := bytes.IndexByte(p.buffer, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buffer[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buffer = p.buffer[i+1:]
+	}
+}
+
+// flush writes the last line if it does not end with a newline.
+func (p *prefixWriter) flush() error {
+	if len(p.buffer) == 0 {
+		return nil
+	}
+	line := append(p.buffer, '\n')
+	p.buffer = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, err := fmt.Fprintf(p.w, "%v%s", p.prefix, line)
+	return err
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestSplitCommand(t *testing.T) {
+	for _, tc := range []struct {
+		command  string
+		expected []string
+	}{
+		{command: "go build  ./...", expected: []string{"go", "build", "./..."}},
+		{command: `echo 'a  b' "c 'd'" e\ f`, expected: []string{"echo", "a  b", "c 'd'", "e f"}},
+		{command: `echo "a \"b\" \c" 'd\'`, expected: []string{"echo", `a "b" \c`, `d\`}},
+		{command: `C:\tools\go.exe ""`, expected: []string{`C:\tools\go.exe`, ""}},
+	} {
+		words, err := splitCommand(tc.command)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, words, tc.expected)
+	}
+	_, err := splitCommand("echo 'a")
+	assert.Error(t, err, "Unterminated quote in [echo 'a]")
+}
+
+func TestParseCommands(t *testing.T) {
+	commands, err := parseCommands([]string{"npm run build", "--", "go", "build", "./...", "--"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, commands, [][]string{{"npm", "run", "build"}, {"go", "build", "./..."}})
+	assert.DeepEqual(t, commandLabels(commands), []string{"npm | ", "go  | "})
+	assert.DeepEqual(t, commandLabels([][]string{{"go"}, {"/usr/bin/go"}, {`go.exe`}}), []string{"go   | ", "go#2 | ", "go#3 | "})
+
+	_, err = parseCommands([]string{"--"})
+	assert.Error(t, err, "No command to run")
+}
+
+func TestRunParallel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands require sh")
+	}
+	run := func(opts parallelOptions, commands ...string) (string, string, error) {
+		var parsed [][]string
+		for _, command := range commands {
+			parsed = append(parsed, []string{"sh", "-c", command})
+		}
+		if opts.jobs == 0 {
+			opts.jobs = len(parsed)
+		}
+		var stdout, stderr bytes.Buffer
+		err := runParallel(parsed, opts, nil, &stdout, &stderr)
+		return stdout.String(), stderr.String(), err
+	}
+
+	stdout, stderr, err := run(parallelOptions{jobs: 1}, "echo a; printf b", "echo c >&2")
+	assert.NilError(t, err)
+	assert.Equal(t, stdout, "sh   | a\nsh   | b\n")
+	assert.Equal(t, stderr, "sh#2 | c\n")
+
+	_, stderr, err = run(parallelOptions{}, "sleep 0.2; exit 3", "exit 1", "true")
+	assert.Equal(t, err, exitError(1))
+	assert.Equal(t, stderr, "sh#2 | [sh -c exit 1] failed: exit status 1\nsh   | [sh -c sleep 0.2; exit 3] failed: exit status 3\n")
+	_, _, err = run(parallelOptions{worst: true}, "sleep 0.2; exit 3", "exit 1", "true")
+	assert.Equal(t, err, exitError(3))
+
+	start := time.Now()
+	_, _, err = run(parallelOptions{failFast: true}, "sleep 60", "exit 2")
+	assert.Equal(t, err, exitError(2))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+	_, stderr, err = run(parallelOptions{failFast: true, worst: true}, "sleep 60", "exit 2")
+	assert.Equal(t, err, exitError(2))
+	assert.Equal(t, stderr, "sh#2 | [sh -c exit 2] failed: exit status 2\n")
+
+	stdout, _, err = run(parallelOptions{jobs: 1, failFast: true}, "exit 2", "echo never")
+	assert.Equal(t, err, exitError(2))
+	assert.Equal(t, stdout, "")
+}
+
+func TestRunParallelForwardsSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands require sh")
+	}
+	signals := make(chan os.Signal, 1)
+	result := make(chan error)
+	go func() {
+		var out bytes.Buffer
+		result <- runParallel([][]string{{"sleep", "60"}, {"sh", "-c", "sleep 60"}}, parallelOptions{jobs: 2}, signals, &out, &out)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	signals <- os.Interrupt
+	select {
+	case err := <-result:
+		assert.Equal(t, err, exitError(130))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the commands were not stopped")
+	}
+}
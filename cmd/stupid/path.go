@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var pathOperations = []string{"abs", "rel", "dir", "base", "ext", "stem", "join", "clean", "to-slash", "from-slash"}
+
+func pathUtil(args []string) error {
+	flags := newFlagSet("path")
+	native := flags.Bool("native", false, "")
+	slash := flags.Bool("slash", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *native && *slash {
+		return fmt.Errorf("Only one of --native and --slash allowed")
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("Expected an operation among %v and paths", pathOperations)
+	}
+	results, err := pathOperation(args[0], args[1:], !*slash)
+	if err != nil {
+		return err
+	}
+	fmt.Print(strings.Join(results, "\n"))
+	return nil
+}
+
+// pathOperation applies op to the expanded paths and returns the results
+// with forward slashes, or with the separator of the platform if native is
+// set.
+func pathOperation(op string, paths []string, native bool) ([]string, error) {
+	expanded := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		if expanded[i], err = expand(path); err != nil {
+			return nil, err
+		}
+		expanded[i] = filepath.FromSlash(expanded[i])
+	}
+	var results []string
+	switch op {
+	case "join":
+		results = []string{filepath.Join(expanded...)}
+	case "to-slash":
+		native = false
+		results = expanded
+	case "from-slash":
+		native = true
+		results = expanded
+	case "rel":
+		if len(expanded) < 2 {
+			return nil, fmt.Errorf("Expected a base and paths")
+		}
+		base, err := filepath.Abs(expanded[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range expanded[1:] {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(base, abs)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, rel)
+		}
+	default:
+		f, ok := map[string]func(string) (string, error){
+			"abs":   filepath.Abs,
+			"dir":   func(p string) (string, error) { return filepath.Dir(p), nil },
+			"base":  func(p string) (string, error) { return filepath.Base(p), nil },
+			"ext":   func(p string) (string, error) { return filepath.Ext(p), nil },
+			"stem":  func(p string) (string, error) { return strings.TrimSuffix(filepath.Base(p), filepath.Ext(p)), nil },
+			"clean": func(p string) (string, error) { return filepath.Clean(p), nil },
+		}[op]
+		if !ok {
+			return nil, fmt.Errorf("Unknown operation [%v], expected one of %v", op, pathOperations)
+		}
+		for _, path := range expanded {
+			result, err := f(path)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	for i, result := range results {
+		if native {
+			results[i] = filepath.FromSlash(result)
+		} else {
+			results[i] = filepath.ToSlash(result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"gotest.tools/assert"
+)
+
+func TestPathOperation(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	home, err := homedir.Dir()
+	assert.NilError(t, err)
+	cwd, home = filepath.ToSlash(cwd), filepath.ToSlash(home)
+
+	for _, tc := range []struct {
+		op       string
+		paths    []string
+		expected []string
+	}{
+		{op: "abs", paths: []string{"foo/bar"}, expected: []string{cwd + "/foo/bar"}},
+		{op: "abs", paths: []string{"~/foo"}, expected: []string{home + "/foo"}},
+		{op: "rel", paths: []string{"foo", "foo/bar/qix.txt", "other"}, expected: []string{"bar/qix.txt", "../other"}},
+		{op: "rel", paths: []string{cwd, "foo/bar"}, expected: []string{"foo/bar"}},
+		{op: "dir", paths: []string{"foo/bar/qix.txt", "qix.txt"}, expected: []string{"foo/bar", "."}},
+		{op: "base", paths: []string{"foo/bar/qix.tar.gz", "foo/bar/"}, expected: []string{"qix.tar.gz", "bar"}},
+		{op: "ext", paths: []string{"foo/bar/qix.tar.gz", "foo/bar"}, expected: []string{".gz", ""}},
+		{op: "stem", paths: []string{"foo/bar/qix.txt", "my file.tar.gz"}, expected: []string{"qix", "my file.tar"}},
+		{op: "join", paths: []string{"foo", "bar/", "qix.txt"}, expected: []string{"foo/bar/qix.txt"}},
+		{op: "clean", paths: []string{"foo//bar/../qix/."}, expected: []string{"foo/qix"}},
+		{op: "to-slash", paths: []string{"foo/bar"}, expected: []string{"foo/bar"}},
+		{op: "from-slash", paths: []string{"foo/bar"}, expected: []string{filepath.FromSlash("foo/bar")}},
+	} {
+		results, err := pathOperation(tc.op, tc.paths, false)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, results, tc.expected)
+	}
+
+	results, err := pathOperation("join", []string{"foo", "bar"}, true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results, []string{filepath.Join("foo", "bar")})
+
+	_, err = pathOperation("split", []string{"foo"}, false)
+	assert.Error(t, err, "Unknown operation [split], expected one of [abs rel dir base ext stem join clean to-slash from-slash]")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+func render(args []string) error {
+	flags := newFlagSet("render")
+	var dataFiles, sets stringsFlag
+	flags.Var(&dataFiles, "data", "")
+	flags.Var(&sets, "set", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("Expected a template and a destination")
+	}
+	data, err := templateData(dataFiles, sets)
+	if err != nil {
+		return err
+	}
+	return renderTemplate(args[0], args[1], data)
+}
+
+// templateData merges the data files, then sets the KEY=VALUE pairs, where
+// dots in keys separate nested keys.
+func templateData(files, sets []string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for _, file := range files {
+		content, err := readData(file)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := content.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected [%v] to contain a mapping", file)
+		}
+		for key, value := range values {
+			data[key] = value
+		}
+	}
+	for _, set := range sets {
+		i := strings.IndexByte(set, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid value [%v], expected KEY=VALUE", set)
+		}
+		keys := strings.Split(set[:i], ".")
+		values := data
+		for _, key := range keys[:len(keys)-1] {
+			nested, ok := values[key].(map[string]interface{})
+			if !ok {
+				nested = map[string]interface{}{}
+				values[key] = nested
+			}
+			values = nested
+		}
+		values[keys[len(keys)-1]] = set[i+1:]
+	}
+	return data, nil
+}
+
+// templateFuncs are the functions available to the templates, which mirror
+// the commands of stupid.
+var templateFuncs = template.FuncMap{
+	"env": func(name string, fallback ...string) (string, error) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		if len(fallback) > 0 {
+			return fallback[0], nil
+		}
+		return "", fmt.Errorf("Environment variable [%v] is not set", name)
+	},
+	"date": func(layout ...string) (string, error) {
+		opts := dateOptions{format: time.RFC3339}
+		if len(layout) > 0 {
+			opts.format = layout[0]
+		}
+		return formatDate(opts)
+	},
+	"home": func() (string, error) {
+		return namedDir("home")
+	},
+	"dir": namedDir,
+	"path": func(op string, paths ...string) (string, error) {
+		results, err := pathOperation(op, paths, false)
+		return strings.Join(results, "\n"), err
+	},
+}
+
+// renderTemplate executes the text template src, with the mode of which
+// dst is created, or written to the standard output if dst is "-".
+func renderTemplate(src, dst string, data map[string]interface{}) error {
+	src, err := expand(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(filepath.Base(src)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return err
+	}
+	if dst == "-" {
+		_, err := os.Stdout.Write(b.Bytes())
+		return err
+	}
+	if dst, err = expand(dst); err != nil {
+		return err
+	}
+	fmt.Printf("Rendering [%v] to [%v]\n", src, dst)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	// A read-only template gives a read-only DST, which is made writable
+	// to be rendered again.
+	writable := info.Mode() | 0200
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Chmod(dst, writable); err != nil {
+			return err
+		}
+	}
+	if err := ioutil.WriteFile(dst, b.Bytes(), writable); err != nil {
+		return err
+	}
+	return os.Chmod(dst, info.Mode())
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestTemplateData(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("base.json", `{"name": "app", "replicas": 1000000, "db": {"host": "db", "port": 5432}}`),
+		fs.WithFile("prod.yaml", "db:\n  host: prod-db\n"),
+		fs.WithFile("prod.env", "# Secrets\nexport TOKEN='s3cr3t # not a comment'\nGREETING=\"hello\\nworld\" # comment\nEMPTY=\n"),
+		fs.WithFile("list.yml", "- a\n"),
+		fs.WithFile("data.xml", "<data/>"))
+	defer rootDirectory.Remove()
+
+	data, err := templateData([]string{rootDirectory.Join("base.json"), rootDirectory.Join("prod.yaml"), rootDirectory.Join("prod.env")},
+		[]string{"version=1.2.0", "db.user=admin", "name.first=x=y"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, map[string]interface{}{
+		"name":     map[string]interface{}{"first": "x=y"},
+		"replicas": json.Number("1000000"),
+		"db":       map[string]interface{}{"host": "prod-db", "user": "admin"},
+		"TOKEN":    "s3cr3t # not a comment",
+		"GREETING": "hello\nworld",
+		"EMPTY":    "",
+		"version":  "1.2.0",
+	})
+
+	_, err = templateData([]string{rootDirectory.Join("list.yml")}, nil)
+	assert.Error(t, err, "Expected ["+rootDirectory.Join("list.yml")+"] to contain a mapping")
+	_, err = templateData([]string{rootDirectory.Join("data.xml")}, nil)
+	assert.Error(t, err, "Unknown format of ["+rootDirectory.Join("data.xml")+"], expected one of [json yaml toml env]")
+	_, err = templateData(nil, []string{"version"})
+	assert.Error(t, err, "Invalid value [version], expected KEY=VALUE")
+}
+
+func TestRenderTemplate(t *testing.T) {
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	os.Setenv("SOURCE_DATE_EPOCH", "1520435045")
+	defer os.Unsetenv("STUPID_RENDER")
+	os.Setenv("STUPID_RENDER", "rendered")
+	home, err := namedDir("home")
+	assert.NilError(t, err)
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("run.sh.tmpl", `#!/bin/sh
+# {{ .name }} built on {{ date "%Y-%m-%d" }}
+{{ env "STUPID_RENDER" }} {{ env "STUPID_MISSING" "default" }}
+{{ path "join" home ".config" .name }}{{ range .db.ports }} {{ . }}{{ end }}
+`, fs.WithMode(0755)),
+		fs.WithFile("missing.tmpl", "{{ .db.missing }}"))
+	defer rootDirectory.Remove()
+
+	data := map[string]interface{}{
+		"name": "app",
+		"db":   map[string]interface{}{"ports": []interface{}{int64(1), int64(2)}},
+	}
+	err = renderTemplate(rootDirectory.Join("run.sh.tmpl"), rootDirectory.Join("out", "bin", "run.sh"), data)
+	assert.NilError(t, err)
+
+	mode := os.FileMode(0755)
+	if runtime.GOOS == "windows" {
+		mode = 0666
+	}
+	expected := fs.Expected(t,
+		fs.WithFile("run.sh.tmpl", "", fs.MatchAnyFileContent, fs.WithMode(mode)),
+		fs.WithFile("missing.tmpl", "", fs.MatchAnyFileContent),
+		fs.WithDir("out",
+			fs.WithDir("bin",
+				fs.WithFile("run.sh", `#!/bin/sh
+# app built on 2018-03-07
+rendered default
+`+filepath.ToSlash(home)+`/.config/app 1 2
+`, fs.WithMode(mode)))))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+
+	err = renderTemplate(rootDirectory.Join("missing.tmpl"), rootDirectory.Join("missing"), data)
+	assert.ErrorContains(t, err, `map has no entry for key "missing"`)
+	err = renderTemplate(rootDirectory.Join("run.sh.tmpl"), rootDirectory.Join("missing"), nil)
+	assert.ErrorContains(t, err, `map has no entry for key "name"`)
+	_, err = os.Stat(rootDirectory.Join("missing"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestRenderReadOnlyTemplate(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("config.tmpl", "name = {{ .name }}\n", fs.WithMode(0444)))
+	defer rootDirectory.Remove()
+
+	for _, name := range []string{"app", "other"} {
+		err := renderTemplate(rootDirectory.Join("config.tmpl"), rootDirectory.Join("config.toml"), map[string]interface{}{"name": name})
+		assert.NilError(t, err)
+	}
+	expected := fs.Expected(t,
+		fs.WithFile("config.tmpl", "name = {{ .name }}\n", fs.WithMode(0444)),
+		fs.WithFile("config.toml", "name = other\n", fs.WithMode(0444)))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+type replaceOptions struct {
+	pattern string
+	with    string
+	literal bool
+	// count is the maximum number of replacements per file, 0 for all.
+	count int
+	// expect is the expected number of replacements in all the files, or
+	// -1 if any number is fine.
+	expect int
+}
+
+func replace(args []string) error {
+	var opts replaceOptions
+	flags := newFlagSet("replace")
+	flags.StringVar(&opts.pattern, "regex", "", "")
+	flags.StringVar(&opts.with, "with", "", "")
+	flags.BoolVar(&opts.literal, "literal", false, "")
+	flags.IntVar(&opts.count, "count", 0, "")
+	flags.IntVar(&opts.expect, "expect", -1, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if opts.pattern == "" {
+		return fmt.Errorf("Expected --regex PATTERN")
+	}
+	// An empty replacement deletes the matches, so it must be explicit.
+	hasWith := false
+	flags.Visit(func(f *flag.Flag) {
+		hasWith = hasWith || f.Name == "with"
+	})
+	if !hasWith {
+		return fmt.Errorf("Expected --with REPL")
+	}
+	return replaceInFiles(args, opts)
+}
+
+// replaceInFiles replaces the matches of the pattern in the globbed
+// sources. No file is written unless the total number of replacements is
+// the expected one.
+func replaceInFiles(sources []string, opts replaceOptions) error {
+	pattern := opts.pattern
+	if opts.literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("Invalid pattern [%v]: %v", opts.pattern, err)
+	}
+	sources, err = glob(sources, true)
+	if err != nil {
+		return err
+	}
+	contents := make([][]byte, len(sources))
+	counts := make([]int, len(sources))
+	total := 0
+	for i, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("Source [%v] is a directory", source)
+		}
+		content, err := ioutil.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		contents[i], counts[i] = replaceAll(re, content, opts)
+		total += counts[i]
+	}
+	if opts.expect >= 0 && total != opts.expect {
+		return fmt.Errorf("Expected %v replacements, found %v", opts.expect, total)
+	}
+	for i, source := range sources {
+		fmt.Printf("Replacing %v occurrences in [%v]\n", counts[i], source)
+		if counts[i] == 0 {
+			continue
+		}
+		if err := writeFileAtomically(source, contents[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceAll replaces up to opts.count matches of re, expanding $1 or
+// ${name} in the replacement unless it is literal.
+func replaceAll(re *regexp.Regexp, content []byte, opts replaceOptions) ([]byte, int) {
+	limit := -1
+	if opts.count > 0 {
+		limit = opts.count
+	}
+	matches := re.FindAllSubmatchIndex(content, limit)
+	if len(matches) == 0 {
+		return content, 0
+	}
+	var replaced []byte
+	last := 0
+	for _, match := range matches {
+		replaced = append(replaced, content[last:match[0]]...)
+		if opts.literal {
+			replaced = append(replaced, opts.with...)
+		} else {
+			replaced = re.Expand(replaced, []byte(opts.with), content, match)
+		}
+		last = match[1]
+	}
+	return append(replaced, content[last:]...), len(matches)
+}
+
+// writeFileAtomically replaces the content of the file by writing a
+// temporary file next to it, with the same mode, and renaming it. The
+// symbolic links are followed so that they are kept.
+func writeFileAtomically(path string, content []byte) error {
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
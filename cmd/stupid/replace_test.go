@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestReplaceInFiles(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("package.json", `{"name": "app", "version": "1.0.0", "engines": {"version": "8.0.0"}}`),
+		fs.WithFile("Info.plist", "<key>CFBundleVersion</key>\n<string>1.0.0</string>\n", fs.WithMode(0755)),
+		fs.WithFile("README.md", "Version 1.0.0 is [the best]\n"))
+	defer rootDirectory.Remove()
+
+	err := replaceInFiles([]string{rootDirectory.Join("*")}, replaceOptions{pattern: `1\.0\.0`, with: "1.1.0", expect: 2})
+	assert.Error(t, err, "Expected 2 replacements, found 3")
+	err = replaceInFiles([]string{rootDirectory.Join("*")}, replaceOptions{pattern: `"version": "[^"]*"`, with: `"version": "1.1.0"`, count: 1, expect: 1})
+	assert.NilError(t, err)
+	err = replaceInFiles([]string{rootDirectory.Join("*.plist")}, replaceOptions{pattern: `<string>(\d+)\.(\d+)\.\d+</string>`, with: "<string>${1}.$2.1</string>", expect: -1})
+	assert.NilError(t, err)
+	err = replaceInFiles([]string{rootDirectory.Join("README.md")}, replaceOptions{pattern: "[the best]", with: "$1", literal: true, expect: 1})
+	assert.NilError(t, err)
+
+	mode := os.FileMode(0755)
+	if runtime.GOOS == "windows" {
+		mode = 0666
+	}
+	expected := fs.Expected(t,
+		fs.WithFile("package.json", `{"name": "app", "version": "1.1.0", "engines": {"version": "8.0.0"}}`),
+		fs.WithFile("Info.plist", "<key>CFBundleVersion</key>\n<string>1.0.1</string>\n", fs.WithMode(mode)),
+		fs.WithFile("README.md", "Version 1.0.0 is $1\n"))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+
+	err = replaceInFiles([]string{rootDirectory.Path()}, replaceOptions{pattern: "a", expect: -1})
+	assert.Error(t, err, "Source ["+rootDirectory.Path()+"] is a directory")
+	err = replaceInFiles([]string{rootDirectory.Path()}, replaceOptions{pattern: "(", expect: -1})
+	assert.ErrorContains(t, err, "Invalid pattern [(]")
+}
+
+func TestReplaceKeepsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on windows")
+	}
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("target", fs.WithFile("version.txt", "1.0.0\n")),
+		fs.WithSymlink("version.txt", filepath.Join("target", "version.txt")))
+	defer rootDirectory.Remove()
+
+	err := replaceInFiles([]string{rootDirectory.Join("version.txt")}, replaceOptions{pattern: "1.0.0", with: "1.1.0", literal: true, expect: 1})
+	assert.NilError(t, err)
+	info, err := os.Lstat(rootDirectory.Join("version.txt"))
+	assert.NilError(t, err)
+	assert.Assert(t, info.Mode()&os.ModeSymlink != 0)
+	content, err := ioutil.ReadFile(rootDirectory.Join("target", "version.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "1.1.0\n")
+}
+
+func TestReplaceRequiresWith(t *testing.T) {
+	assert.Error(t, replace([]string{"--regex", "a", "foo.txt"}), "Expected --with REPL")
+}
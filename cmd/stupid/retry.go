@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func retry(args []string) error {
+	flags := newFlagSet("retry")
+	attempts := flags.Int("attempts", 3, "")
+	delay := flags.Duration("delay", time.Second, "")
+	backoff := flags.String("backoff", "constant", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No command to run")
+	}
+	if *attempts < 1 {
+		return fmt.Errorf("Invalid attempts [%v], expected at least 1", *attempts)
+	}
+	return retryCommand(*attempts, *delay, *backoff, args[0], args[1:]...)
+}
+
+// retryCommand runs the command until it succeeds, at most attempts times,
+// waiting between the attempts for a delay which is constant, or grows
+// linearly or exponentially. It gives up as soon as the command is
+// interrupted by a signal.
+func retryCommand(attempts int, delay time.Duration, backoff string, name string, args ...string) error {
+	switch backoff {
+	case "constant", "linear", "exp":
+	default:
+		return fmt.Errorf("Unknown backoff [%v], expected one of [constant linear exp]", backoff)
+	}
+	commandLine := strings.Join(append([]string{name}, args...), " ")
+	for attempt := 1; ; attempt++ {
+		err := command(name, args...).Run()
+		if _, failed := exitCode(err).(exitError); !failed || attempt == attempts || signaled(err) {
+			return exitCode(err)
+		}
+		wait := backoffDelay(delay, backoff, attempt)
+		fmt.Fprintf(os.Stderr, "Attempt %v/%v of [%v] failed: %v, retrying in %v\n", attempt, attempts, commandLine, err, wait)
+		time.Sleep(wait)
+	}
+}
+
+// maxBackoffDelay caps the delays growing with the attempts, which would
+// otherwise overflow with many attempts.
+const maxBackoffDelay = time.Hour
+
+// backoffDelay returns the delay to wait after a failed attempt, which grows
+// up to maxBackoffDelay unless the initial delay is already longer.
+func backoffDelay(delay time.Duration, backoff string, attempt int) time.Duration {
+	if delay <= 0 || delay >= maxBackoffDelay {
+		return delay
+	}
+	switch backoff {
+	case "linear":
+		if time.Duration(attempt) < maxBackoffDelay/delay {
+			return delay * time.Duration(attempt)
+		}
+		return maxBackoffDelay
+	case "exp":
+		wait := delay
+		for i := 1; i < attempt && wait < maxBackoffDelay; i++ {
+			wait *= 2
+		}
+		if wait < maxBackoffDelay {
+			return wait
+		}
+		return maxBackoffDelay
+	}
+	return delay
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestRetryCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands require sh")
+	}
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+	out := rootDirectory.Join("out.txt")
+	// Fails until it ran three times.
+	flaky := "echo run >> " + out + "; test $(wc -l < " + out + ") -ge 3"
+
+	assert.Equal(t, retryCommand(2, time.Millisecond, "constant", "sh", "-c", flaky), exitError(1))
+	assert.NilError(t, ioutil.WriteFile(out, nil, 0644))
+	start := time.Now()
+	assert.NilError(t, retryCommand(5, 20*time.Millisecond, "exp", "sh", "-c", flaky))
+	assert.Assert(t, time.Since(start) >= 60*time.Millisecond)
+	content, err := ioutil.ReadFile(out)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "run\nrun\nrun\n")
+
+	assert.Equal(t, retryCommand(3, time.Millisecond, "constant", "sh", "-c", "echo run >> "+out+"; kill -TERM $$"), exitError(143))
+	content, err = ioutil.ReadFile(out)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "run\nrun\nrun\nrun\n")
+
+	assert.Error(t, retryCommand(3, 0, "random", "true"), "Unknown backoff [random], expected one of [constant linear exp]")
+}
+
+func TestBackoffDelay(t *testing.T) {
+	for _, tc := range []struct {
+		delay    time.Duration
+		backoff  string
+		attempt  int
+		expected time.Duration
+	}{
+		{delay: time.Second, backoff: "constant", attempt: 3, expected: time.Second},
+		{delay: time.Second, backoff: "linear", attempt: 3, expected: 3 * time.Second},
+		{delay: time.Second, backoff: "exp", attempt: 1, expected: time.Second},
+		{delay: time.Second, backoff: "exp", attempt: 3, expected: 4 * time.Second},
+		{delay: time.Second, backoff: "exp", attempt: 100, expected: time.Hour},
+		{delay: time.Second, backoff: "linear", attempt: 1 << 30, expected: time.Hour},
+		{delay: 2 * time.Hour, backoff: "exp", attempt: 100, expected: 2 * time.Hour},
+		{delay: 0, backoff: "exp", attempt: 100, expected: 0},
+	} {
+		assert.Equal(t, backoffDelay(tc.delay, tc.backoff, tc.attempt), tc.expected)
+	}
+}
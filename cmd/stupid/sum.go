@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func sum(args []string) error {
+	flags := newFlagSet("sum")
+	algo := flags.String("algo", "", "")
+	check := flags.String("check", "", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *check != "" {
+		return checkSums(os.Stdout, *check, *algo)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No source files")
+	}
+	if *algo == "" {
+		*algo = "sha256"
+	}
+	return sumFiles(os.Stdout, *algo, args)
+}
+
+// sumFiles writes the checksums of the globbed sources in the coreutils
+// format, directories being processed recursively.
+func sumFiles(w io.Writer, algo string, sources []string) error {
+	sources, err := glob(sources, true)
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			sum, err := hashFile(algo, path)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%v  %v\n", sum, filepath.ToSlash(path))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSums verifies the checksums listed in file. If algo is empty it is
+// guessed from the length of each checksum. Files which cannot be read are
+// reported as failed, and the remaining ones still checked.
+func checkSums(w io.Writer, file, algo string) error {
+	file, err := expand(file)
+	if err != nil {
+		return err
+	}
+	if _, ok := hashes[algo]; algo != "" && !ok {
+		return fmt.Errorf("Unknown algorithm [%v], expected one of [md5 sha1 sha256 sha512]", algo)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	failures, unreadable := 0, 0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 2)
+		if len(fields) != 2 || len(fields[1]) < 2 || (fields[1][0] != ' ' && fields[1][0] != '*') {
+			return fmt.Errorf("Invalid checksum line %v in [%v]", line, file)
+		}
+		expected, path := strings.ToLower(fields[0]), filepath.FromSlash(fields[1][1:])
+		lineAlgo := algo
+		if lineAlgo == "" {
+			lineAlgo = algoOf(expected)
+		}
+		actual, err := hashFile(lineAlgo, path)
+		if err != nil {
+			fmt.Fprintf(w, "%v: FAILED open or read\n", fields[1][1:])
+			unreadable++
+		} else if actual == expected {
+			fmt.Fprintf(w, "%v: OK\n", fields[1][1:])
+		} else {
+			fmt.Fprintf(w, "%v: FAILED\n", fields[1][1:])
+			failures++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	var problems []string
+	if unreadable > 0 {
+		problems = append(problems, fmt.Sprintf("%v listed files could not be read", unreadable))
+	}
+	if failures > 0 {
+		problems = append(problems, fmt.Sprintf("%v computed checksums did NOT match", failures))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%v", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func algoOf(sum string) string {
+	switch len(sum) {
+	case 2 * md5.Size:
+		return "md5"
+	case 2 * sha1.Size:
+		return "sha1"
+	case 2 * sha512.Size:
+		return "sha512"
+	}
+	return "sha256"
+}
+
+func hashFile(algo, path string) (string, error) {
+	newHash, ok := hashes[algo]
+	if !ok {
+		return "", fmt.Errorf("Unknown algorithm [%v], expected one of [md5 sha1 sha256 sha512]", algo)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
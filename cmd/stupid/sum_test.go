@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestSumTree(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo\n"),
+		fs.WithDir("bar",
+			fs.WithFile("bar.txt", "bar\n")))
+	defer rootDirectory.Remove()
+
+	root := filepath.ToSlash(rootDirectory.Path())
+	var out bytes.Buffer
+	err := sumFiles(&out, "sha256", []string{filepath.Join(rootDirectory.Path(), "*")})
+	assert.NilError(t, err)
+	assert.Equal(t, out.String(),
+		"7d865e959b2466918c9863afca942d0fb89d7c9ac0c99bafc3749504ded97730  "+root+"/bar/bar.txt\n"+
+			"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c  "+root+"/foo.txt\n")
+
+	out.Reset()
+	err = sumFiles(&out, "md5", []string{filepath.Join(rootDirectory.Path(), "foo.txt")})
+	assert.NilError(t, err)
+	assert.Equal(t, out.String(), "d3b07384d113edec49eaa6238ad5ff00  "+root+"/foo.txt\n")
+
+	err = sumFiles(&out, "crc32", []string{filepath.Join(rootDirectory.Path(), "foo.txt")})
+	assert.Error(t, err, "Unknown algorithm [crc32], expected one of [md5 sha1 sha256 sha512]")
+}
+
+func TestCheckSums(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo\n"),
+		fs.WithFile("bar.txt", "bar\n"))
+	defer rootDirectory.Remove()
+
+	root := filepath.ToSlash(rootDirectory.Path())
+	sums := filepath.Join(rootDirectory.Path(), "SUMS")
+	err := ioutil.WriteFile(sums, []byte(
+		"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c  "+root+"/foo.txt\n"+
+			"c157a79031e1c40f85931829bc5fc552 *"+root+"/bar.txt\r\n"), 0644)
+	assert.NilError(t, err)
+	var out bytes.Buffer
+	err = checkSums(&out, sums, "")
+	assert.NilError(t, err)
+	assert.Equal(t, out.String(), root+"/foo.txt: OK\n"+root+"/bar.txt: OK\n")
+
+	err = ioutil.WriteFile(filepath.Join(rootDirectory.Path(), "bar.txt"), []byte("qix\n"), 0644)
+	assert.NilError(t, err)
+	out.Reset()
+	err = checkSums(&out, sums, "")
+	assert.Error(t, err, "1 computed checksums did NOT match")
+	assert.Equal(t, out.String(), root+"/foo.txt: OK\n"+root+"/bar.txt: FAILED\n")
+
+	err = ioutil.WriteFile(sums, []byte(
+		"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c  "+root+"/missing.txt\n"+
+			"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c  "+root+"/foo.txt\n"), 0644)
+	assert.NilError(t, err)
+	out.Reset()
+	err = checkSums(&out, sums, "")
+	assert.Error(t, err, "1 listed files could not be read")
+	assert.Equal(t, out.String(), root+"/missing.txt: FAILED open or read\n"+root+"/foo.txt: OK\n")
+	err = checkSums(&out, sums, "crc32")
+	assert.Error(t, err, "Unknown algorithm [crc32], expected one of [md5 sha1 sha256 sha512]")
+}
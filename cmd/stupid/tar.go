@@ -78,11 +78,19 @@ func tarFiles(dst string, srcs ...string) error {
 }
 
 func untar(src, dst string) error {
+	dst, err := expand(dst)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Untaring [%v] to [%v]\n", src, dst)
 	var r io.Reader
 	if src == "-" {
 		r = os.Stdin
 	} else {
+		src, err = expand(src)
+		if err != nil {
+			return err
+		}
 		f, err := os.Open(src)
 		if err != nil {
 			return err
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+)
+
+func mktemp(args []string) error {
+	flags := newFlagSet("mktemp")
+	dir := flags.Bool("d", false, "")
+	prefix := flags.String("prefix", "stupid", "")
+	in := flags.String("in", "", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	path, err := makeTemp(*in, *prefix, *dir)
+	if err != nil {
+		return err
+	}
+	fmt.Print(path)
+	return nil
+}
+
+// makeTemp creates a new temporary file, or directory if dir is set, in the
+// directory in, creating it if needed, or in the default temporary directory.
+func makeTemp(in, prefix string, dir bool) (string, error) {
+	if in != "" {
+		var err error
+		if in, err = expand(in); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(in, 0755); err != nil {
+			return "", err
+		}
+	}
+	if dir {
+		return ioutil.TempDir(in, prefix)
+	}
+	f, err := ioutil.TempFile(in, prefix)
+	if err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+func run(args []string) error {
+	flags := newFlagSet("run")
+	tmpdir := flags.String("tmpdir", "", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No command to run")
+	}
+	if *tmpdir == "" {
+		return fmt.Errorf("Expected --tmpdir VAR")
+	}
+	return runWithTempDir(*tmpdir, args[0], args[1:]...)
+}
+
+// runWithTempDir runs the command with the variable set to a new temporary
+// directory, which is removed once the command exits, failing if it cannot
+// be.
+func runWithTempDir(variable, name string, args ...string) error {
+	dir, err := makeTemp("", "stupid", true)
+	if err != nil {
+		return err
+	}
+	// The command receives the interrupt as well, wait for it to clean up.
+	// The signal is caught rather than ignored, which the command would
+	// inherit.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	cmd := command(name, args...)
+	cmd.Env = append(os.Environ(), variable+"="+dir)
+	err = exitCode(cmd.Run())
+	if removeErr := os.RemoveAll(dir); removeErr != nil {
+		if err == nil {
+			return removeErr
+		}
+		// The failure of the command matters most, but still report the
+		// directory left behind.
+		fmt.Fprintln(os.Stderr, removeErr)
+	}
+	return err
+}
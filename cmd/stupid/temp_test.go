@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestMakeTemp(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+
+	in := filepath.Join(rootDirectory.Path(), "tmp")
+	file, err := makeTemp(in, "scratch", false)
+	assert.NilError(t, err)
+	dir, err := makeTemp(in, "scratch", true)
+	assert.NilError(t, err)
+	assert.Assert(t, file != dir)
+
+	for path, isDir := range map[string]bool{file: false, dir: true} {
+		assert.Equal(t, filepath.Dir(path), in)
+		assert.Assert(t, strings.HasPrefix(filepath.Base(path), "scratch"), path)
+		info, err := os.Stat(path)
+		assert.NilError(t, err)
+		assert.Equal(t, info.IsDir(), isDir, path)
+	}
+}
+
+func TestRunWithTempDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands require sh")
+	}
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+
+	out := rootDirectory.Join("dir.txt")
+	err := runWithTempDir("SCRATCH", "sh", "-c", `mkdir "$SCRATCH/sub" && printf %s "$SCRATCH" > `+out+` && exit 3`)
+	assert.Equal(t, err, exitError(3))
+	dir, err := ioutil.ReadFile(out)
+	assert.NilError(t, err)
+	assert.Assert(t, len(dir) > 0)
+	_, err = os.Stat(string(dir))
+	assert.Assert(t, os.IsNotExist(err))
+}
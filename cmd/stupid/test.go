@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var predicates = []string{"e", "f", "d", "L", "s", "x", "newer", "empty", "any"}
+
+func test(args []string) error {
+	flags := newFlagSet("test")
+	selected := map[string]*bool{}
+	for _, predicate := range predicates {
+		selected[predicate] = flags.Bool(predicate, false, "")
+	}
+	printResult := flags.Bool("print", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	predicate := ""
+	for _, p := range predicates {
+		if *selected[p] {
+			if predicate != "" {
+				return fmt.Errorf("Only one predicate allowed among %v", predicates)
+			}
+			predicate = p
+		}
+	}
+	ok, err := testFiles(predicate, args)
+	if err != nil {
+		return err
+	}
+	if *printResult {
+		fmt.Print(ok)
+		return nil
+	}
+	if !ok {
+		return exitError(1)
+	}
+	return nil
+}
+
+// testFiles reports whether all paths satisfy the predicate, or for "any"
+// whether any of the globs matches an existing path.
+func testFiles(predicate string, paths []string) (bool, error) {
+	if predicate == "" {
+		return false, fmt.Errorf("Expected one predicate among %v", predicates)
+	}
+	if len(paths) == 0 || predicate == "newer" && len(paths) != 2 {
+		return false, fmt.Errorf("Not enough arguments for [%v]", predicate)
+	}
+	expanded := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		if expanded[i], err = expand(path); err != nil {
+			return false, err
+		}
+	}
+	paths = expanded
+	if predicate == "newer" {
+		return isNewer(paths[0], paths[1])
+	}
+	if predicate == "any" {
+		for _, path := range paths {
+			matches, err := filepath.Glob(path)
+			if err != nil || len(matches) > 0 {
+				return err == nil, err
+			}
+		}
+		return false, nil
+	}
+	for _, path := range paths {
+		ok, err := testFile(predicate, path)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func testFile(predicate, path string) (bool, error) {
+	if predicate == "L" {
+		info, err := os.Lstat(path)
+		return err == nil && info.Mode()&os.ModeSymlink != 0, nil
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	switch predicate {
+	case "f":
+		return info.Mode().IsRegular(), nil
+	case "d":
+		return info.IsDir(), nil
+	case "s":
+		return info.Size() > 0, nil
+	case "x":
+		return isExecutable(path), nil
+	case "empty":
+		return isEmptyDir(path, info)
+	}
+	return true, nil
+}
+
+// isNewer reports whether a exists and is newer than b, or b does not exist.
+func isNewer(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return infoA.ModTime().After(infoB.ModTime()), nil
+}
+
+func isEmptyDir(path string, info os.FileInfo) (bool, error) {
+	if !info.IsDir() {
+		return false, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
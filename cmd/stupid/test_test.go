@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestTestFiles(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("empty.txt", ""),
+		fs.WithFile("foo.txt", "foo"),
+		fs.WithDir("empty-dir"),
+		fs.WithDir("full-dir",
+			fs.WithFile("bar.txt", "bar")))
+	defer rootDirectory.Remove()
+
+	path := func(name string) string {
+		return filepath.Join(rootDirectory.Path(), name)
+	}
+	for _, tc := range []struct {
+		predicate string
+		paths     []string
+		expected  bool
+	}{
+		{predicate: "e", paths: []string{path("foo.txt"), path("empty-dir")}, expected: true},
+		{predicate: "e", paths: []string{path("foo.txt"), path("non-existing")}, expected: false},
+		{predicate: "f", paths: []string{path("foo.txt")}, expected: true},
+		{predicate: "f", paths: []string{path("empty-dir")}, expected: false},
+		{predicate: "d", paths: []string{path("empty-dir")}, expected: true},
+		{predicate: "d", paths: []string{path("foo.txt")}, expected: false},
+		{predicate: "s", paths: []string{path("foo.txt")}, expected: true},
+		{predicate: "s", paths: []string{path("empty.txt")}, expected: false},
+		{predicate: "L", paths: []string{path("foo.txt")}, expected: false},
+		{predicate: "empty", paths: []string{path("empty-dir")}, expected: true},
+		{predicate: "empty", paths: []string{path("full-dir")}, expected: false},
+		{predicate: "empty", paths: []string{path("empty.txt")}, expected: false},
+		{predicate: "any", paths: []string{path("*.go"), path("full-dir/*.txt")}, expected: true},
+		{predicate: "any", paths: []string{path("*.go")}, expected: false},
+		{predicate: "newer", paths: []string{path("foo.txt"), path("non-existing")}, expected: true},
+		{predicate: "newer", paths: []string{path("non-existing"), path("foo.txt")}, expected: false},
+	} {
+		ok, err := testFiles(tc.predicate, tc.paths)
+		assert.NilError(t, err)
+		assert.Equal(t, ok, tc.expected, tc.predicate, tc.paths)
+	}
+}
+
+func TestTestNewer(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("old.txt", ""),
+		fs.WithFile("new.txt", ""))
+	defer rootDirectory.Remove()
+
+	old := filepath.Join(rootDirectory.Path(), "old.txt")
+	mtime := time.Now().Add(-time.Hour)
+	assert.NilError(t, os.Chtimes(old, mtime, mtime))
+	new := filepath.Join(rootDirectory.Path(), "new.txt")
+
+	ok, err := testFiles("newer", []string{new, old})
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	ok, err = testFiles("newer", []string{old, new})
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+
+	_, err = testFiles("newer", []string{old})
+	assert.Error(t, err, "Not enough arguments for [newer]")
+}
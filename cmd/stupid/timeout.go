@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func timeout(args []string) error {
+	flags := newFlagSet("timeout")
+	killAfter := flags.Duration("kill-after", 0, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("Expected a duration and a command to run")
+	}
+	duration, err := parseTimeout(args[0])
+	if err != nil {
+		return err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	return timeoutCommand(duration, *killAfter, signals, args[1], args[2:]...)
+}
+
+// parseTimeout parses a duration such as 1m30s, or a number of seconds.
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid duration [%v]", value)
+	}
+	return d, nil
+}
+
+// timeoutCommand runs the command, stopping it along with the processes it
+// started once the timeout expires, and killing them if they are still
+// running killAfter later, if set. Signals are forwarded to the command.
+// Like GNU timeout, it exits with 124 on timeout, or 137 if the command had
+// to be killed.
+func timeoutCommand(timeout, killAfter time.Duration, signals <-chan os.Signal, name string, args ...string) error {
+	cmd := command(name, args...)
+	p, err := startProcess(cmd)
+	if err != nil {
+		return err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	var kill <-chan time.Time
+	timedOut, killed := false, false
+	for {
+		select {
+		case err := <-p.done:
+			switch {
+			case killed:
+				return exitError(128 + int(syscall.SIGKILL))
+			case timedOut:
+				return exitError(124)
+			}
+			return exitCode(err)
+		case sig := <-signals:
+			if s, ok := sig.(syscall.Signal); ok {
+				signalProcessGroup(cmd, s)
+			}
+		case <-timer.C:
+			fmt.Fprintf(os.Stderr, "[%v] timed out after %v\n", strings.Join(cmd.Args, " "), timeout)
+			timedOut = true
+			signalProcessGroup(cmd, syscall.SIGTERM)
+			if killAfter > 0 {
+				kill = time.After(killAfter)
+			}
+		case <-kill:
+			killed = true
+			signalProcessGroup(cmd, syscall.SIGKILL)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestParseTimeout(t *testing.T) {
+	d, err := parseTimeout("1m30s")
+	assert.NilError(t, err)
+	assert.Equal(t, d, 90*time.Second)
+	d, err = parseTimeout("1.5")
+	assert.NilError(t, err)
+	assert.Equal(t, d, 1500*time.Millisecond)
+	_, err = parseTimeout("soon")
+	assert.Error(t, err, "Invalid duration [soon]")
+}
+
+func TestTimeoutCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands require sh")
+	}
+	assert.NilError(t, timeoutCommand(time.Minute, 0, nil, "true"))
+	assert.Equal(t, timeoutCommand(time.Minute, 0, nil, "sh", "-c", "exit 3"), exitError(3))
+
+	// The sleep started by sh must be stopped as well for the command to return.
+	start := time.Now()
+	assert.Equal(t, timeoutCommand(50*time.Millisecond, 0, nil, "sh", "-c", "sleep 60; echo done"), exitError(124))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+
+	start = time.Now()
+	assert.Equal(t, timeoutCommand(50*time.Millisecond, 50*time.Millisecond, nil, "sh", "-c", "trap '' TERM; sleep 60"), exitError(137))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+	assert.Equal(t, timeoutCommand(time.Minute, 0, signals, "sleep", "60"), exitError(130))
+}
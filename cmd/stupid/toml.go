@@ -0,0 +1,40 @@
+package main
+
+import (
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML parses a TOML document. The arrays of tables are returned as
+// arrays, and the dates as RFC 3339 strings.
+func parseTOML(content string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	if _, err := toml.Decode(content, &data); err != nil {
+		return nil, err
+	}
+	normalizeTOML(data)
+	return data, nil
+}
+
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+	case []map[string]interface{}:
+		tables := make([]interface{}, len(v))
+		for i, table := range v {
+			tables[i] = normalizeTOML(table)
+		}
+		return tables
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return value
+}
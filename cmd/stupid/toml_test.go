@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseTOML(t *testing.T) {
+	for _, tc := range []struct {
+		toml     string
+		expected map[string]interface{}
+	}{
+		{toml: "", expected: map[string]interface{}{}},
+		{toml: "# comment only\n", expected: map[string]interface{}{}},
+		{toml: "title = \"TOML \\\"example\\\"\" # comment\npath = 'C:\\Users'\n\"quoted key\" = true\nint = +1_000\nfloat = -1.5e3\nno = false\n", expected: map[string]interface{}{
+			"title": `TOML "example"`, "path": `C:\Users`, "quoted key": true, "int": int64(1000), "float": -1500.0, "no": false,
+		}},
+		{toml: "date = 1979-05-27T07:32:00Z\noffset = 1979-05-27T00:32:00.5-07:00\nversion = \"1.2.0\"\n", expected: map[string]interface{}{
+			"date": "1979-05-27T07:32:00Z", "offset": "1979-05-27T00:32:00.5-07:00", "version": "1.2.0",
+		}},
+		{toml: "[server]\nhost = \"localhost\"\nports = [\n  8080,\n  8081, # comment\n]\n\n[server.tls.cert]\npath = \"cert.pem\"\n", expected: map[string]interface{}{
+			"server": map[string]interface{}{
+				"host":  "localhost",
+				"ports": []interface{}{int64(8080), int64(8081)},
+				"tls":   map[string]interface{}{"cert": map[string]interface{}{"path": "cert.pem"}},
+			},
+		}},
+		{toml: "[[servers]]\nname = \"a\"\n[servers.db]\nport = 1\n[[servers]]\nname = \"b\"\nlimits = { cpu = 2, tags = [\"x\", 'y'] }\n", expected: map[string]interface{}{
+			"servers": []interface{}{
+				map[string]interface{}{"name": "a", "db": map[string]interface{}{"port": int64(1)}},
+				map[string]interface{}{"name": "b", "limits": map[string]interface{}{"cpu": int64(2), "tags": []interface{}{"x", "y"}}},
+			},
+		}},
+		{toml: "basic = \"\"\"\nline 1\nline \\\n    2\\u00e9\"\"\"\nliteral = '''\nraw \\n\n'''\n", expected: map[string]interface{}{
+			"basic":   "line 1\nline 2é",
+			"literal": "raw \\n\n",
+		}},
+	} {
+		value, err := parseTOML(tc.toml)
+		assert.NilError(t, err, tc.toml)
+		assert.DeepEqual(t, value, tc.expected)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	for _, tc := range []struct {
+		toml     string
+		expected string
+	}{
+		{toml: "a = 1\na = 2\n", expected: "Near line 2 (last key parsed 'a'): Key 'a' has already been defined."},
+		{toml: "a = 1 b = 2\n", expected: "Near line 1 (last key parsed ''): expected a top-level item to end with a newline, comment, or EOF, but got 'b' instead"},
+		{toml: "\na = \"open\n", expected: "Near line 2 (last key parsed 'a'): strings cannot contain newlines"},
+		{toml: "a = what\n", expected: "Near line 1 (last key parsed 'a'): expected value but found \"what\" instead"},
+		{toml: "a = [1, \"b\"]\n", expected: "Near line 1 (last key parsed 'a'): Array contains values of type 'Integer' and 'String', but arrays must be homogeneous."},
+	} {
+		_, err := parseTOML(tc.toml)
+		assert.Error(t, err, tc.expected)
+	}
+}
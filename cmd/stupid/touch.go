@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func touch(args []string) error {
+	flags := newFlagSet("touch")
+	date := flags.String("date", "", "")
+	reference := flags.String("reference", "", "")
+	noCreate := flags.Bool("no-create", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No files to touch")
+	}
+	t := time.Now()
+	if *date != "" && *reference != "" {
+		return fmt.Errorf("Only one of --date and --reference allowed")
+	}
+	if *date != "" {
+		if t, err = time.Parse(time.RFC3339, *date); err != nil {
+			return err
+		}
+	}
+	if *reference != "" {
+		if t, err = dateOf(*reference); err != nil {
+			return err
+		}
+	}
+	return touchFiles(args, t, !*noCreate)
+}
+
+func touchFiles(sources []string, t time.Time, create bool) error {
+	for _, source := range sources {
+		source, err := expand(source)
+		if err != nil {
+			return err
+		}
+		paths := []string{source}
+		if strings.ContainsAny(source, "*?[") {
+			if paths, err = filepath.Glob(source); err != nil {
+				return err
+			}
+		}
+		for _, path := range paths {
+			if err := touchFile(path, t, create); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func touchFile(path string, t time.Time, create bool) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if !create {
+			return nil
+		}
+		fmt.Printf("Creating [%v]\n", path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		fmt.Printf("Touching [%v]\n", path)
+	}
+	return os.Chtimes(path, t, t)
+}
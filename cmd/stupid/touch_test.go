@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestTouch(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo"),
+		fs.WithFile("bar.txt", "bar"))
+	defer rootDirectory.Remove()
+
+	date := time.Date(2018, time.March, 7, 15, 4, 5, 0, time.UTC)
+	err := touchFiles([]string{
+		filepath.Join(rootDirectory.Path(), "*.txt"),
+		filepath.Join(rootDirectory.Path(), "non-existing*"),
+		filepath.Join(rootDirectory.Path(), "sub-dir", ".built"),
+	}, date, true)
+	assert.NilError(t, err)
+
+	expected := fs.Expected(t,
+		fs.WithFile("foo.txt", "foo"),
+		fs.WithFile("bar.txt", "bar"),
+		fs.WithDir("sub-dir",
+			fs.WithFile(".built", "")))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+	for _, name := range []string{"foo.txt", "bar.txt", filepath.Join("sub-dir", ".built")} {
+		info, err := os.Stat(filepath.Join(rootDirectory.Path(), name))
+		assert.NilError(t, err)
+		assert.Assert(t, info.ModTime().Equal(date), name)
+	}
+}
+
+func TestTouchNoCreate(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithFile("foo.txt", "foo"))
+	defer rootDirectory.Remove()
+
+	err := touchFiles([]string{
+		filepath.Join(rootDirectory.Path(), "foo.txt"),
+		filepath.Join(rootDirectory.Path(), "bar.txt"),
+	}, time.Now(), false)
+	assert.NilError(t, err)
+
+	expected := fs.Expected(t, fs.WithFile("foo.txt", "foo"))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+}
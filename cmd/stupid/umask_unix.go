@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import "syscall"
+
+// umask returns the file mode creation mask of the process.
+func umask() uint32 {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return uint32(mask)
+}
@@ -0,0 +1,7 @@
+package main
+
+// umask returns the file mode creation mask of the process, which Windows
+// does not have.
+func umask() uint32 {
+	return 0
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Build metadata, injected with -ldflags "-X main.version=v1.2.3 ...", or
+// read from the build information embedded by the go command otherwise.
+var (
+	version   string
+	revision  string
+	buildTime string
+)
+
+func printVersion(args []string) error {
+	flags := newFlagSet("version")
+	require := flags.String("require", "", "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("Unexpected arguments %v", args)
+	}
+	v, r, t := buildInfo()
+	if *require != "" {
+		return checkVersion(v, *require)
+	}
+	fmt.Printf("Version:    %v\n", v)
+	fmt.Printf("Revision:   %v\n", r)
+	fmt.Printf("Build time: %v\n", t)
+	fmt.Printf("Go version: %v\n", runtime.Version())
+	return nil
+}
+
+func buildInfo() (string, string, string) {
+	v, r, t := version, revision, buildTime
+	embeddedVersion, embeddedRevision, embeddedTime := readBuildInfo()
+	if v == "" {
+		v = embeddedVersion
+	}
+	if r == "" {
+		r = embeddedRevision
+	}
+	if t == "" {
+		t = embeddedTime
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+	if r == "" {
+		r = "unknown"
+	}
+	if t == "" {
+		t = "unknown"
+	}
+	return v, r, t
+}
+
+// checkVersion fails unless version satisfies all the comma separated
+// constraints of require, e.g. ">=1.2,<2".
+func checkVersion(version, require string) error {
+	if version == "(devel)" {
+		return fmt.Errorf("stupid is a development build, version unknown, cannot check %v", require)
+	}
+	for _, constraint := range strings.Split(require, ",") {
+		constraint = strings.TrimSpace(constraint)
+		required := strings.TrimLeft(constraint, "<>=!")
+		op := constraint[:len(constraint)-len(required)]
+		cmp, err := compareVersions(version, strings.TrimSpace(required))
+		if err != nil {
+			return fmt.Errorf("Cannot check version [%v] against [%v]: %v", version, require, err)
+		}
+		ok := false
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		default:
+			return fmt.Errorf("Invalid version constraint [%v]", constraint)
+		}
+		if !ok {
+			return fmt.Errorf("stupid version %v does not satisfy %v", version, require)
+		}
+	}
+	return nil
+}
+
+// compareVersions compares two versions such as v1.2.3 or 1.2, missing
+// components being zero and pre-releases, e.g. 1.2.0-rc1, preceding the
+// release. The numbers within pre-releases are compared as numbers, so that
+// rc10 follows rc2.
+func compareVersions(a, b string) (int, error) {
+	numbersA, preA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	numbersB, preB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(numbersA) || i < len(numbersB); i++ {
+		var x, y int
+		if i < len(numbersA) {
+			x = numbersA[i]
+		}
+		if i < len(numbersB) {
+			y = numbersB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0, nil
+	case preA == "":
+		return 1, nil
+	case preB == "":
+		return -1, nil
+	}
+	return comparePrereleases(preA, preB), nil
+}
+
+// comparePrereleases compares the runs of digits of a and b as numbers,
+// which precede any other text, and the rest as strings.
+func comparePrereleases(a, b string) int {
+	for a != "" && b != "" {
+		var chunkA, chunkB string
+		chunkA, a = splitChunk(a)
+		chunkB, b = splitChunk(b)
+		numberA, numberB := isDigit(chunkA[0]), isDigit(chunkB[0])
+		switch {
+		case numberA && !numberB:
+			return -1
+		case !numberA && numberB:
+			return 1
+		case numberA:
+			chunkA, chunkB = strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			if len(chunkA) != len(chunkB) {
+				if len(chunkA) < len(chunkB) {
+					return -1
+				}
+				return 1
+			}
+		}
+		if chunkA != chunkB {
+			if chunkA < chunkB {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+// splitChunk splits the leading run of digits, or of other characters, of s.
+func splitChunk(s string) (string, string) {
+	i := 1
+	for i < len(s) && isDigit(s[i]) == isDigit(s[0]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parseVersion(version string) ([]int, string, error) {
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	var numbers []int
+	for _, s := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, "", fmt.Errorf("Invalid version [%v]", version)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, pre, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "v1.2.3", b: "1.2.3", expected: 0},
+		{a: "1.2", b: "1.2.0", expected: 0},
+		{a: "1.10", b: "1.9", expected: 1},
+		{a: "v0.9.1", b: "1", expected: -1},
+		{a: "1.2.0-rc1", b: "1.2.0", expected: -1},
+		{a: "1.2.0", b: "1.2.0-rc1", expected: 1},
+		{a: "1.2.0-rc2", b: "1.2.0-rc1", expected: 1},
+		{a: "1.2.0-rc10", b: "1.2.0-rc2", expected: 1},
+		{a: "1.2.0-rc.2", b: "1.2.0-rc.10", expected: -1},
+		{a: "1.2.0-rc.02", b: "1.2.0-rc.2", expected: 0},
+		{a: "1.2.0-dev.3", b: "1.2.0-dev.3.1", expected: -1},
+		{a: "1.2.0-1", b: "1.2.0-alpha", expected: -1},
+		{a: "1.2.0-beta", b: "1.2.0-alpha.1", expected: 1},
+		{a: "1.2.0+build5", b: "1.2.0", expected: 0},
+	} {
+		cmp, err := compareVersions(tc.a, tc.b)
+		assert.NilError(t, err)
+		assert.Equal(t, cmp, tc.expected, tc.a+" vs "+tc.b)
+	}
+	_, err := compareVersions("(devel)", "1.2")
+	assert.Error(t, err, "Invalid version [(devel)]")
+}
+
+func TestCheckVersion(t *testing.T) {
+	assert.NilError(t, checkVersion("v1.2.3", ">=1.2"))
+	assert.NilError(t, checkVersion("v1.2.3", ">=1.2, <2"))
+	assert.NilError(t, checkVersion("v1.2.3", "1.2.3"))
+	assert.NilError(t, checkVersion("v1.2.3", "!=1.2.4"))
+	assert.Error(t, checkVersion("v1.1.0", ">=1.2"), "stupid version v1.1.0 does not satisfy >=1.2")
+	assert.Error(t, checkVersion("v2.0.0", ">=1.2,<2"), "stupid version v2.0.0 does not satisfy >=1.2,<2")
+	assert.Error(t, checkVersion("v1.2.3", "=>1.2"), "Invalid version constraint [=>1.2]")
+	assert.Error(t, checkVersion("(devel)", ">=1.2"), "stupid is a development build, version unknown, cannot check >=1.2")
+	assert.Error(t, checkVersion("dev", ">=1.2"), "Cannot check version [dev] against [>=1.2]: Invalid version [dev]")
+}
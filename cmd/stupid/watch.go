@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type watchOptions struct {
+	paths    stringsFlag
+	excludes stringsFlag
+	debounce time.Duration
+	interval time.Duration
+	queue    bool
+}
+
+// fileState is what is compared to detect changes when polling.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func watch(args []string) error {
+	var opts watchOptions
+	var current *stringsFlag
+	flags := newFlagSet("watch")
+	flags.Var(listFlag{&opts.paths, &current}, "path", "")
+	flags.Var(&opts.excludes, "exclude", "")
+	flags.DurationVar(&opts.debounce, "debounce", 300*time.Millisecond, "")
+	flags.DurationVar(&opts.interval, "interval", 500*time.Millisecond, "")
+	flags.BoolVar(&opts.queue, "queue", false, "")
+	args, err := parseListFlags(flags, args, &current)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("No command to run")
+	}
+	if len(opts.paths) == 0 {
+		return fmt.Errorf("Expected at least one --path SRCS")
+	}
+	if opts.interval <= 0 {
+		return fmt.Errorf("Invalid interval [%v], expected a positive duration", opts.interval)
+	}
+	if opts.debounce < 0 {
+		return fmt.Errorf("Invalid debounce [%v], expected a positive or zero duration", opts.debounce)
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	return watchFiles(opts, signals, args[0], args[1:]...)
+}
+
+// watchFiles runs the command, then polls the files matching the paths and
+// restarts it, or runs it again once it exits if opts.queue is set, when
+// they change. It returns once a signal is received, after stopping the
+// command.
+func watchFiles(opts watchOptions, signals <-chan os.Signal, name string, args ...string) error {
+	previous, err := snapshotFiles(opts.paths, opts.excludes)
+	if err != nil {
+		return err
+	}
+	commandLine := strings.Join(append([]string{name}, args...), " ")
+	var running *process
+	var done <-chan error
+	start := func() {
+		fmt.Printf("Running [%v]\n", commandLine)
+		cmd := command(name, args...)
+		cmd.Stdin = nil
+		p, err := startProcess(cmd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		running, done = p, p.done
+	}
+	stop := func() {
+		if running != nil {
+			running.stop(5 * time.Second)
+			running, done = nil, nil
+		}
+	}
+	start()
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+	var changed time.Time
+	pending, queued := false, false
+	for {
+		select {
+		case sig := <-signals:
+			stop()
+			if s, ok := sig.(syscall.Signal); ok {
+				return exitError(128 + int(s))
+			}
+			return exitError(1)
+		case err := <-done:
+			running, done = nil, nil
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[%v] failed: %v\n", commandLine, err)
+			}
+			if queued {
+				queued = false
+				start()
+			} else {
+				fmt.Println("Waiting for changes")
+			}
+		case now := <-ticker.C:
+			files, err := snapshotFiles(opts.paths, opts.excludes)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			if path := changedFile(previous, files); path != "" {
+				fmt.Printf("Changed [%v]\n", path)
+				previous, changed, pending = files, now, true
+			}
+			if !pending || now.Sub(changed) < opts.debounce {
+				continue
+			}
+			pending = false
+			switch {
+			case running == nil:
+				start()
+			case opts.queue:
+				queued = true
+			default:
+				stop()
+				start()
+			}
+		}
+	}
+}
+
+func snapshotFiles(paths, excludes []string) (map[string]fileState, error) {
+	files, err := globFiles(paths, excludes)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		states[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states, nil
+}
+
+// changedFile returns a file which was created, modified or removed between
+// the two snapshots, or an empty string if there are no changes.
+func changedFile(previous, current map[string]fileState) string {
+	for file, state := range current {
+		if before, ok := previous[file]; !ok || before.size != state.size || !before.modTime.Equal(state.modTime) {
+			return file
+		}
+	}
+	for file := range previous {
+		if _, ok := current[file]; !ok {
+			return file
+		}
+	}
+	return ""
+}
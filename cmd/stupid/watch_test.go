@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestChangedFile(t *testing.T) {
+	now := time.Now()
+	previous := map[string]fileState{"a": {now, 1}, "b": {now, 2}}
+	assert.Equal(t, changedFile(previous, map[string]fileState{"a": {now, 1}, "b": {now, 2}}), "")
+	assert.Equal(t, changedFile(previous, map[string]fileState{"a": {now, 1}, "b": {now, 3}}), "b")
+	assert.Equal(t, changedFile(previous, map[string]fileState{"a": {now.Add(time.Second), 1}, "b": {now, 2}}), "a")
+	assert.Equal(t, changedFile(previous, map[string]fileState{"a": {now, 1}}), "b")
+	assert.Equal(t, changedFile(previous, map[string]fileState{"a": {now, 1}, "b": {now, 2}, "c": {now, 0}}), "c")
+}
+
+func TestWatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command requires sh")
+	}
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("src",
+			fs.WithFile("main.go", "main"),
+			fs.WithFile("main_test.go", "test")))
+	defer rootDirectory.Remove()
+	out := rootDirectory.Join("out.txt")
+
+	signals := make(chan os.Signal)
+	result := make(chan error)
+	go func() {
+		result <- watchFiles(watchOptions{
+			paths:    stringsFlag{rootDirectory.Join("src")},
+			excludes: stringsFlag{"*_test.go"},
+			debounce: 20 * time.Millisecond,
+			interval: 10 * time.Millisecond,
+		}, signals, "sh", "-c", "echo run >> "+out+" && sleep 60")
+	}()
+	waitForRuns(t, out, 1)
+
+	assert.NilError(t, ioutil.WriteFile(rootDirectory.Join("src", "main_test.go"), []byte("excluded"), 0644))
+	assert.NilError(t, ioutil.WriteFile(rootDirectory.Join("src", "main.go"), []byte("modified"), 0644))
+	waitForRuns(t, out, 2)
+
+	signals <- os.Interrupt
+	select {
+	case err := <-result:
+		assert.Equal(t, err, exitError(130))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the command was not stopped")
+	}
+	content, err := ioutil.ReadFile(out)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "run\nrun\n")
+}
+
+func waitForRuns(t *testing.T, file string, runs int) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		content, _ := ioutil.ReadFile(file)
+		if strings.Count(string(content), "run\n") >= runs {
+			return
+		}
+	}
+	t.Fatalf("the command did not run %v times", runs)
+}
+
+func TestWatchInvalidDurations(t *testing.T) {
+	assert.Error(t, watch([]string{"--interval", "0", "--path", "foo.txt", "--", "true"}), "Invalid interval [0s], expected a positive duration")
+	assert.Error(t, watch([]string{"--interval", "-1s", "--path", "foo.txt", "--", "true"}), "Invalid interval [-1s], expected a positive duration")
+	assert.Error(t, watch([]string{"--debounce", "-1s", "--path", "foo.txt", "--", "true"}), "Invalid debounce [-1s], expected a positive or zero duration")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func which(args []string) error {
+	flags := newFlagSet("which")
+	all := flags.Bool("all", false, "")
+	args, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, name := range args {
+		paths := lookPaths(name, *all, os.Getenv("PATH"), os.Getenv("PATHEXT"))
+		if len(paths) == 0 {
+			missing = append(missing, name)
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Could not find %v in PATH", missing)
+	}
+	return nil
+}
+
+// lookPaths returns the executables named name found in the directories of
+// path, only the first one unless all is set. On Windows the extensions
+// listed in pathext are tried as well.
+func lookPaths(name string, all bool, path, pathext string) []string {
+	candidates := []string{name}
+	if runtime.GOOS == "windows" {
+		candidates = withExtensions(name, pathext)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		for _, candidate := range candidates {
+			if isExecutable(candidate) {
+				return []string{candidate}
+			}
+		}
+		return nil
+	}
+	var found []string
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		for _, candidate := range candidates {
+			if p := filepath.Join(dir, candidate); isExecutable(p) {
+				if !all {
+					return []string{p}
+				}
+				found = append(found, p)
+			}
+		}
+	}
+	return found
+}
+
+func withExtensions(name, pathext string) []string {
+	if pathext == "" {
+		pathext = ".COM;.EXE;.BAT;.CMD"
+	}
+	extensions := strings.Split(strings.ToLower(pathext), ";")
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range extensions {
+		if ext != "" && e == ext {
+			return []string{name}
+		}
+	}
+	candidates := []string{}
+	for _, e := range extensions {
+		if e != "" {
+			candidates = append(candidates, name+e)
+		}
+	}
+	return candidates
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
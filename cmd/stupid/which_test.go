@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestLookPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Executable bits are not supported on Windows")
+	}
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("bin1",
+			fs.WithFile("tool", "", fs.WithMode(0755)),
+			fs.WithFile("data", "", fs.WithMode(0644))),
+		fs.WithDir("bin2",
+			fs.WithFile("tool", "", fs.WithMode(0755)),
+			fs.WithFile("data", "", fs.WithMode(0755))))
+	defer rootDirectory.Remove()
+
+	bin1 := filepath.Join(rootDirectory.Path(), "bin1")
+	bin2 := filepath.Join(rootDirectory.Path(), "bin2")
+	path := bin1 + string(os.PathListSeparator) + bin2
+
+	assert.DeepEqual(t, lookPaths("tool", false, path, ""), []string{filepath.Join(bin1, "tool")})
+	assert.DeepEqual(t, lookPaths("tool", true, path, ""), []string{filepath.Join(bin1, "tool"), filepath.Join(bin2, "tool")})
+	assert.DeepEqual(t, lookPaths("data", true, path, ""), []string{filepath.Join(bin2, "data")})
+	assert.DeepEqual(t, lookPaths(filepath.Join(bin1, "tool"), false, "", ""), []string{filepath.Join(bin1, "tool")})
+	assert.Assert(t, lookPaths("missing", true, path, "") == nil)
+}
+
+func TestWithExtensions(t *testing.T) {
+	assert.DeepEqual(t, withExtensions("go", ".EXE;.BAT"), []string{"go.exe", "go.bat"})
+	assert.DeepEqual(t, withExtensions("go.exe", ".EXE;.BAT"), []string{"go.exe"})
+	assert.DeepEqual(t, withExtensions("go.bin", ""), []string{"go.bin.com", "go.bin.exe", "go.bin.bat", "go.bin.cmd"})
+}
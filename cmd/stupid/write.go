@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func write(args []string, flag int) error {
+	flags := newFlagSet("write")
+	newline := flags.String("newline", "lf", "")
+	escape := flags.Bool("escape", false, "")
+	noNewline := flags.Bool("no-newline", false, "")
+	// Flags are not parsed after FILE, so that TEXT can start with a dash.
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) < 1 {
+		return fmt.Errorf("No destination file")
+	}
+	text := strings.Join(args[1:], " ")
+	var err error
+	if *escape {
+		if text, err = unescape(text); err != nil {
+			return err
+		}
+	}
+	if !*noNewline {
+		text += "\n"
+	}
+	eol, err := lineEnding(*newline)
+	if err != nil {
+		return err
+	}
+	text = strings.Replace(strings.Replace(text, "\r\n", "\n", -1), "\n", eol, -1)
+	return writeFile(args[0], text, flag)
+}
+
+// writeFile writes text to dst, either truncating it with os.O_TRUNC or
+// appending to it with os.O_APPEND.
+func writeFile(dst, text string, flag int) error {
+	dst, err := expand(dst)
+	if err != nil {
+		return err
+	}
+	if flag&os.O_APPEND != 0 {
+		fmt.Printf("Appending to [%v]\n", dst)
+	} else {
+		fmt.Printf("Writing [%v]\n", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|flag, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func lineEnding(newline string) (string, error) {
+	switch newline {
+	case "lf":
+		return "\n", nil
+	case "crlf":
+		return "\r\n", nil
+	case "native":
+		if runtime.GOOS == "windows" {
+			return "\r\n", nil
+		}
+		return "\n", nil
+	}
+	return "", fmt.Errorf("Unknown newline [%v], expected one of [lf crlf native]", newline)
+}
+
+// unescape interprets the Go escape sequences in s, e.g. \n, \t or \x41,
+// leaving invalid UTF-8 bytes unchanged.
+func unescape(s string) (string, error) {
+	var b strings.Builder
+	for len(s) > 0 {
+		if r, size := utf8.DecodeRuneInString(s); r == utf8.RuneError && size == 1 {
+			b.WriteByte(s[0])
+			s = s[1:]
+			continue
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			return "", fmt.Errorf("Invalid escape sequence in [%v]", s)
+		}
+		if multibyte {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(byte(r))
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
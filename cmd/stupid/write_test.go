@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func TestWriteAndAppend(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+
+	dst := filepath.Join(rootDirectory.Path(), "sub-dir", "foo.txt")
+	err := write([]string{dst, "foo", " bar "}, os.O_TRUNC)
+	assert.NilError(t, err)
+	err = write([]string{"--newline", "crlf", "--escape", dst, `qix\tqux\n`}, os.O_APPEND)
+	assert.NilError(t, err)
+	err = write([]string{"--no-newline", dst, "end", "-x"}, os.O_APPEND)
+	assert.NilError(t, err)
+	err = write([]string{"--escape", "--no-newline", dst, "\xff\\xfe\xe9"}, os.O_APPEND)
+	assert.NilError(t, err)
+
+	expected := fs.Expected(t,
+		fs.WithDir("sub-dir",
+			fs.WithFile("foo.txt", "foo  bar \nqix\tqux\r\n\r\nend -x\xff\xfe\xe9")))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+}
+
+func TestWriteWithInvalidOptions(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root")
+	defer rootDirectory.Remove()
+
+	dst := filepath.Join(rootDirectory.Path(), "foo.txt")
+	err := write([]string{"--newline", "cr", dst, "foo"}, os.O_TRUNC)
+	assert.Error(t, err, "Unknown newline [cr], expected one of [lf crlf native]")
+	err = write([]string{"--escape", dst, `foo\q`}, os.O_TRUNC)
+	assert.Error(t, err, `Invalid escape sequence in [\q]`)
+}
+
+func TestCat(t *testing.T) {
+	rootDirectory := fs.NewDir(t, "root",
+		fs.WithDir("source",
+			fs.WithFile("1.txt", "foo\n"),
+			fs.WithFile("2.txt", "bar\n")))
+	defer rootDirectory.Remove()
+
+	err := catFiles(os.Stdout, filepath.Join(rootDirectory.Path(), "destination", "all.txt"), filepath.Join(rootDirectory.Path(), "source", "*.txt"))
+	assert.NilError(t, err)
+
+	expected := fs.Expected(t,
+		fs.WithDir("source",
+			fs.WithFile("1.txt", "foo\n"),
+			fs.WithFile("2.txt", "bar\n")),
+		fs.WithDir("destination",
+			fs.WithFile("all.txt", "foo\nbar\n")))
+	assert.Assert(t, fs.Equal(rootDirectory.Path(), expected))
+
+	var stdout bytes.Buffer
+	err = catFiles(&stdout, "-", filepath.Join(rootDirectory.Path(), "source", "2.txt"), filepath.Join(rootDirectory.Path(), "source", "1.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, stdout.String(), "bar\nfoo\n")
+	_, err = os.Stat(filepath.Join(rootDirectory.Path(), "-"))
+	assert.Assert(t, os.IsNotExist(err))
+}
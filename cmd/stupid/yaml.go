@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// parseYAML parses a YAML document, rejecting the content with several
+// documents. The mappings are converted to have string keys and the
+// integers to be int64, like with the other formats.
+func parseYAML(content string) (interface{}, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil && err != io.EOF {
+		return nil, err
+	}
+	var next interface{}
+	if err := decoder.Decode(&next); err == nil {
+		return nil, fmt.Errorf("Multiple YAML documents are not supported")
+	} else if err != io.EOF {
+		return nil, err
+	}
+	return normalizeYAML(data), nil
+}
+
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	case int:
+		return int64(v)
+	}
+	return value
+}
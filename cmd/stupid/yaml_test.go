@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseYAML(t *testing.T) {
+	for _, tc := range []struct {
+		yaml     string
+		expected interface{}
+	}{
+		{yaml: "", expected: nil},
+		{yaml: "# comment only\n", expected: nil},
+		{yaml: "hello", expected: "hello"},
+		{yaml: "a: 1\nb: two # comment\nc: 'it''s'\nd: \"tab\\t#\"\ne:\nf: ~\n", expected: map[string]interface{}{
+			"a": int64(1), "b": "two", "c": "it's", "d": "tab\t#", "e": nil, "f": nil,
+		}},
+		{yaml: "---\nurl: http://example.com:8080/path\n\"quoted key\": yes\nbool: true\nfloat: 1.5\nversion: 1.2.0\n", expected: map[string]interface{}{
+			"url": "http://example.com:8080/path", "quoted key": true, "bool": true, "float": 1.5, "version": "1.2.0",
+		}},
+		{yaml: "db:\n  host: localhost\n  ports:\n    - 5432\n    - 5433\n  users:\n  - name: admin\n    roles: [read, write]\n  - name: guest\n", expected: map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":  "localhost",
+				"ports": []interface{}{int64(5432), int64(5433)},
+				"users": []interface{}{
+					map[string]interface{}{"name": "admin", "roles": []interface{}{"read", "write"}},
+					map[string]interface{}{"name": "guest"},
+				},
+			},
+		}},
+		{yaml: "- a\n- - b\n  - c\n-\n  d: {e: 1, f: [g, \"h, i\"]}\n- []\n", expected: []interface{}{
+			"a",
+			[]interface{}{"b", "c"},
+			map[string]interface{}{"d": map[string]interface{}{"e": int64(1), "f": []interface{}{"g", "h, i"}}},
+			[]interface{}{},
+		}},
+		{yaml: "literal: |\n  line 1\n\n  # not a comment\n    indented\nfolded: >-\n  a\n  b\n\n  c\nlast: |+\n  keep\n\n", expected: map[string]interface{}{
+			"literal": "line 1\n\n# not a comment\n  indented\n",
+			"folded":  "a b\nc",
+			"last":    "keep\n\n",
+		}},
+		{yaml: "base: &base\n  host: db\n  port: 5432\nprod:\n  <<: *base\n  host: prod-db\nname: &name app\nalias: *name\ntagged: !!str 1.0\nplain: multi\n  line\n", expected: map[string]interface{}{
+			"base":   map[string]interface{}{"host": "db", "port": int64(5432)},
+			"prod":   map[string]interface{}{"host": "prod-db", "port": int64(5432)},
+			"name":   "app",
+			"alias":  "app",
+			"tagged": "1.0",
+			"plain":  "multi line",
+		}},
+		{yaml: "1: one\n2.5: [true]\n", expected: map[string]interface{}{"1": "one", "2.5": []interface{}{true}}},
+	} {
+		value, err := parseYAML(tc.yaml)
+		assert.NilError(t, err, tc.yaml)
+		assert.DeepEqual(t, value, tc.expected)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	for _, tc := range []struct {
+		yaml     string
+		expected string
+	}{
+		{yaml: "a: 1\n  b: 2\n", expected: "yaml: line 2: mapping values are not allowed in this context"},
+		{yaml: "a: [1, 2\n", expected: "yaml: line 1: did not find expected ',' or ']'"},
+		{yaml: "a: 1\n- b\n", expected: "yaml: line 1: did not find expected key"},
+		{yaml: "a: 1\n---\nb: 2\n", expected: "Multiple YAML documents are not supported"},
+		{yaml: "a: *missing\n", expected: "yaml: unknown anchor 'missing' referenced"},
+	} {
+		_, err := parseYAML(tc.yaml)
+		assert.Error(t, err, tc.expected)
+	}
+}